@@ -0,0 +1,175 @@
+package gormutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ppabimanyu/compage/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+/*
+Filter returns a scope applying the filters of the request.
+Columns come from the pagination.Config whitelist and are quoted by the dialector,
+values are always bound as parameters. The like operator matches a substring.
+
+	db.Scopes(gormutils.Filter(req)).Count(&total)
+*/
+func Filter(req *pagination.Request) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, f := range req.Filters {
+			column := clause.Column{Name: f.Column}
+			switch f.Operator {
+			case pagination.Eq:
+				db = db.Where("? = ?", column, f.Value)
+			case pagination.Ne:
+				db = db.Where("? <> ?", column, f.Value)
+			case pagination.Gt:
+				db = db.Where("? > ?", column, f.Value)
+			case pagination.Gte:
+				db = db.Where("? >= ?", column, f.Value)
+			case pagination.Lt:
+				db = db.Where("? < ?", column, f.Value)
+			case pagination.Lte:
+				db = db.Where("? <= ?", column, f.Value)
+			case pagination.Like:
+				db = db.Where(`? LIKE ? ESCAPE '\'`, column, "%"+likeEscaper.Replace(f.Value)+"%")
+			case pagination.In:
+				db = db.Where("? IN ?", column, f.Values)
+			}
+		}
+		return db
+	}
+}
+
+// Sort returns a scope ordering by the sorts of the request.
+func Sort(req *pagination.Request) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, s := range req.Sorts {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: s.Column}, Desc: s.Desc})
+		}
+		return db
+	}
+}
+
+/*
+Paginate returns a scope applying the filters, sorts and page of the request.
+In cursor mode it fetches the rows after the cursor, or before it for a previous cursor
+in reverse order; use FindPage to also build the pagination.Meta.
+
+	var users []User
+	db.Scopes(gormutils.Paginate(req)).Find(&users)
+*/
+func Paginate(req *pagination.Request) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Scopes(Filter(req))
+		if req.Mode != pagination.CursorMode {
+			return db.Scopes(Sort(req)).Offset(req.Offset()).Limit(req.Limit)
+		}
+
+		column, desc := _cursorSort(req)
+		if req.Cursor != nil {
+			// Rows after the cursor in the sort direction, or before it for a previous page
+			operator := ">"
+			if desc != req.Cursor.Prev {
+				operator = "<"
+			}
+			db = db.Where(fmt.Sprintf("? %s ?", operator), clause.Column{Name: column}, req.Cursor.Value)
+		}
+		// A previous page is read backwards from the cursor, FindPage restores the order
+		reverse := req.Cursor != nil && req.Cursor.Prev
+		return db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc != reverse}).Limit(req.Limit)
+	}
+}
+
+func _cursorSort(req *pagination.Request) (string, bool) {
+	if len(req.Sorts) == 0 {
+		return "id", false
+	}
+	return req.Sorts[0].Column, req.Sorts[0].Desc
+}
+
+/*
+FindPage finds a page of T with the request and builds its pagination.Meta.
+
+In offset mode the total is counted with the filters applied.
+In cursor mode one extra row is fetched to know whether another page follows,
+and the cursors point at the first and last rows of the page.
+Every statement runs in a new session, so db can carry conditions and be reused.
+
+	users, meta, err := gormutils.FindPage[User](db.WithContext(ctx), req)
+*/
+func FindPage[T any](db *gorm.DB, req *pagination.Request) ([]T, *pagination.Meta, error) {
+	var items []T
+	if req.Mode != pagination.CursorMode {
+		var total int64
+		if err := db.Session(&gorm.Session{}).Model(new(T)).Scopes(Filter(req)).Count(&total).Error; err != nil {
+			return nil, nil, err
+		}
+		if err := db.Session(&gorm.Session{}).Scopes(Paginate(req)).Find(&items).Error; err != nil {
+			return nil, nil, err
+		}
+		return items, pagination.NewOffsetMeta(req, total), nil
+	}
+
+	extra := *req
+	extra.Limit = req.Limit + 1
+	if err := db.Session(&gorm.Session{}).Scopes(Paginate(&extra)).Find(&items).Error; err != nil {
+		return nil, nil, err
+	}
+
+	prev := req.Cursor != nil && req.Cursor.Prev
+	more := len(items) > req.Limit
+	if more {
+		items = items[:req.Limit]
+	}
+	if prev {
+		slices.Reverse(items)
+	}
+
+	meta := &pagination.Meta{
+		Limit: req.Limit,
+		// Coming back from a next page always leaves a page ahead, and the other way around
+		HasNext: more && !prev || prev,
+		HasPrev: more && prev || req.Cursor != nil && !prev,
+	}
+	if len(items) == 0 {
+		return items, meta, nil
+	}
+
+	column, _ := _cursorSort(req)
+	if meta.HasNext {
+		value, err := _columnValue(db, items[len(items)-1], column)
+		if err != nil {
+			return nil, nil, err
+		}
+		meta.NextCursor = (&pagination.Cursor{Value: value}).Encode()
+	}
+	if meta.HasPrev {
+		value, err := _columnValue(db, items[0], column)
+		if err != nil {
+			return nil, nil, err
+		}
+		meta.PrevCursor = (&pagination.Cursor{Value: value, Prev: true}).Encode()
+	}
+	return items, meta, nil
+}
+
+func _columnValue(db *gorm.DB, item any, column string) (any, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(item); err != nil {
+		return nil, err
+	}
+	field := stmt.Schema.LookUpField(column)
+	if field == nil {
+		return nil, errors.New("cursor column " + column + " not found in " + stmt.Schema.Name)
+	}
+	value, _ := field.ValueOf(context.Background(), reflect.ValueOf(item))
+	return value, nil
+}
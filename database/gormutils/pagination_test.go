@@ -0,0 +1,111 @@
+package gormutils
+
+import (
+	"fmt"
+	"github.com/glebarez/sqlite"
+	"github.com/ppabimanyu/compage/pagination"
+	"gorm.io/gorm"
+	"slices"
+	"testing"
+)
+
+type pageUser struct {
+	ID     int
+	Name   string
+	Active bool
+}
+
+func _pageDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&pageUser{}); err != nil {
+		t.Fatal(err)
+	}
+	var users []pageUser
+	for i := 1; i <= 10; i++ {
+		users = append(users, pageUser{ID: i, Name: fmt.Sprintf("user-%d", i), Active: i != 5})
+	}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func _pageIDs(users []pageUser) []int {
+	ids := make([]int, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+func TestFindPageOffset(t *testing.T) {
+	// The conditions of the caller must apply to both the count and the find
+	db := _pageDB(t).Where("active = ?", true)
+	req := &pagination.Request{
+		Page:    2,
+		Limit:   3,
+		Sorts:   []pagination.Sort{{Column: "id", Desc: true}},
+		Filters: []pagination.Filter{{Column: "id", Operator: pagination.Gt, Value: "1"}},
+	}
+
+	users, meta, err := FindPage[pageUser](db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := _pageIDs(users); !slices.Equal(ids, []int{7, 6, 4}) {
+		t.Errorf("unexpected page %v", ids)
+	}
+	if meta.Total != 8 || meta.TotalPages != 3 || !meta.HasNext || !meta.HasPrev {
+		t.Errorf("unexpected meta %+v", meta)
+	}
+
+	req.Page = 3
+	users, meta, err = FindPage[pageUser](db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := _pageIDs(users); !slices.Equal(ids, []int{3, 2}) || meta.Total != 8 || meta.HasNext {
+		t.Errorf("unexpected last page %v %+v", ids, meta)
+	}
+}
+
+func TestFindPageCursor(t *testing.T) {
+	db := _pageDB(t).Where("active = ?", true)
+	req := &pagination.Request{Mode: pagination.CursorMode, Limit: 4}
+
+	first, meta, err := FindPage[pageUser](db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := _pageIDs(first); !slices.Equal(ids, []int{1, 2, 3, 4}) || !meta.HasNext || meta.HasPrev {
+		t.Fatalf("unexpected first page %v %+v", ids, meta)
+	}
+
+	req.Cursor, err = pagination.DecodeCursor(meta.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, meta, err := FindPage[pageUser](db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := _pageIDs(second); !slices.Equal(ids, []int{6, 7, 8, 9}) || !meta.HasNext || !meta.HasPrev {
+		t.Fatalf("unexpected second page %v %+v", ids, meta)
+	}
+
+	req.Cursor, err = pagination.DecodeCursor(meta.PrevCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, meta, err := FindPage[pageUser](db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := _pageIDs(back); !slices.Equal(ids, []int{1, 2, 3, 4}) || !meta.HasNext || meta.HasPrev {
+		t.Errorf("unexpected previous page %v %+v", ids, meta)
+	}
+}
@@ -0,0 +1,251 @@
+package gormutils
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ppabimanyu/compage/reqctx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrMissingTenant is returned for the statements on tenant scoped tables run without
+	// a tenant in the context, see TenantConfig.AllowMissingTenant and SkipTenant.
+	ErrMissingTenant = errors.New("gormutils: missing tenant in context")
+
+	// ErrInvalidTenantSchema is returned when the schema name of a tenant is not a plain identifier.
+	ErrInvalidTenantSchema = errors.New("gormutils: invalid tenant schema")
+
+	// ErrTenantUpsert is returned for the upserts on tenant scoped tables in ColumnTenantMode
+	// when the database cannot restrict the update to the tenant, e.g. SQL Server.
+	ErrTenantUpsert = errors.New("gormutils: upsert not supported on tenant scoped tables")
+)
+
+const _skipTenantKey = "gormutils:skip_tenant"
+
+var schemaNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// TenantMode selects how the TenantPlugin isolates the data of the tenants.
+type TenantMode int
+
+const (
+	// ColumnTenantMode stores the tenants in the same tables, the rows of the models with
+	// the tenant column are filtered and created with the tenant ID.
+	ColumnTenantMode TenantMode = iota
+
+	// SchemaTenantMode stores each tenant in its own schema, the tables are qualified with
+	// the schema of the tenant, e.g. "tenant_acme"."users". The schemas and their tables are
+	// created by the application.
+	SchemaTenantMode
+)
+
+type TenantConfig struct {
+	// Mode selects how the data of the tenants is isolated.
+	// Default is ColumnTenantMode.
+	Mode TenantMode
+
+	// Column is the database column holding the tenant ID in ColumnTenantMode.
+	// Only the models with this column are scoped.
+	// Default is "tenant_id".
+	Column string
+
+	// SchemaName returns the schema of a tenant in SchemaTenantMode.
+	// Default is "tenant_" followed by the lowercased tenant ID, with dashes replaced by underscores.
+	SchemaName func(tenantID string) string
+
+	// AllowMissingTenant runs the statements without a tenant in the context unscoped.
+	// Default is false, they fail with ErrMissingTenant.
+	AllowMissingTenant bool
+}
+
+// TenantPlugin scopes the statements by the tenant ID of the context, set with reqctx.WithTenantID
+// or by http.TenantMiddleware, so the statements must be run with db.WithContext(ctx).
+// Joined tables and raw SQL are not scoped.
+type TenantPlugin struct {
+	config *TenantConfig
+}
+
+/*
+NewTenantPlugin returns the gorm plugin scoping the statements by tenant. Register it with db.Use,
+or through the Plugins of the postgres and sqlserver connection configs:
+
+	db, err := postgres.NewConnection(&postgres.Config{
+		Plugins: []gorm.Plugin{gormutils.NewTenantPlugin(nil)},
+	})
+	db.WithContext(c.UserContext()).Find(&users)
+*/
+func NewTenantPlugin(config *TenantConfig) *TenantPlugin {
+	if config == nil {
+		config = &TenantConfig{}
+	}
+	if config.Column == "" {
+		config.Column = "tenant_id"
+	}
+	if config.SchemaName == nil {
+		config.SchemaName = func(tenantID string) string {
+			return "tenant_" + strings.ReplaceAll(strings.ToLower(tenantID), "-", "_")
+		}
+	}
+	return &TenantPlugin{config: config}
+}
+
+func (p *TenantPlugin) Name() string {
+	return "gormutils:tenant"
+}
+
+func (p *TenantPlugin) Initialize(db *gorm.DB) error {
+	return errors.Join(
+		db.Callback().Create().Before("gorm:create").Register("gormutils:tenant_create", p._create),
+		db.Callback().Query().Before("gorm:query").Register("gormutils:tenant_query", p._scope),
+		db.Callback().Update().Before("gorm:update").Register("gormutils:tenant_update", p._scopeWrite),
+		db.Callback().Delete().Before("gorm:delete").Register("gormutils:tenant_delete", p._scopeWrite),
+		db.Callback().Row().Before("gorm:row").Register("gormutils:tenant_row", p._scope),
+	)
+}
+
+// SkipTenant is a scope running the statement unscoped, e.g. for administration tasks.
+//
+//	db.Scopes(gormutils.SkipTenant).Find(&tenants)
+func SkipTenant(db *gorm.DB) *gorm.DB {
+	return db.Set(_skipTenantKey, true)
+}
+
+// _tenant returns the tenant ID of the statement, and false when the statement must not be scoped.
+func (p *TenantPlugin) _tenant(db *gorm.DB) (string, bool) {
+	if db.Error != nil || db.Statement.SQL.Len() > 0 {
+		return "", false
+	}
+	if p.config.Mode == ColumnTenantMode && (db.Statement.Schema == nil || db.Statement.Schema.LookUpField(p.config.Column) == nil) {
+		return "", false
+	}
+	if p.config.Mode == SchemaTenantMode && (db.Statement.Table == "" || db.Statement.TableExpr != nil) {
+		return "", false
+	}
+	if skip, ok := db.Get(_skipTenantKey); ok && skip == true {
+		return "", false
+	}
+	tenantID := reqctx.TenantIDFrom(db.Statement.Context)
+	if tenantID == "" {
+		if !p.config.AllowMissingTenant {
+			_ = db.AddError(ErrMissingTenant)
+		}
+		return "", false
+	}
+	return tenantID, true
+}
+
+// _qualifyTable qualifies the table of the statement with the schema of the tenant.
+func (p *TenantPlugin) _qualifyTable(db *gorm.DB, tenantID string) {
+	if strings.Contains(db.Statement.Table, ".") {
+		return
+	}
+	name := p.config.SchemaName(tenantID)
+	if !schemaNamePattern.MatchString(name) {
+		_ = db.AddError(fmt.Errorf("%w: %q", ErrInvalidTenantSchema, name))
+		return
+	}
+	db.Statement.Table = name + "." + db.Statement.Table
+}
+
+func (p *TenantPlugin) _scope(db *gorm.DB) {
+	if tenantID, ok := p._tenant(db); ok {
+		p._scopeTenant(db, tenantID)
+	}
+}
+
+func (p *TenantPlugin) _scopeWrite(db *gorm.DB) {
+	tenantID, ok := p._tenant(db)
+	if !ok {
+		return
+	}
+	if p.config.Mode == ColumnTenantMode && _isGlobalWrite(db) {
+		// Let gorm reject the update or delete without conditions instead of running it on the whole tenant
+		return
+	}
+	p._scopeTenant(db, tenantID)
+}
+
+func (p *TenantPlugin) _scopeTenant(db *gorm.DB, tenantID string) {
+	if p.config.Mode == SchemaTenantMode {
+		p._qualifyTable(db, tenantID)
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: p.config.Column}, Value: tenantID},
+	}})
+}
+
+func (p *TenantPlugin) _create(db *gorm.DB) {
+	tenantID, ok := p._tenant(db)
+	if !ok {
+		return
+	}
+	if p.config.Mode == SchemaTenantMode {
+		p._qualifyTable(db, tenantID)
+		return
+	}
+
+	field := db.Statement.Schema.LookUpField(p.config.Column)
+	ctx := db.Statement.Context
+	switch value := db.Statement.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := field.Set(ctx, reflect.Indirect(value.Index(i)), tenantID); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		_ = db.AddError(field.Set(ctx, value, tenantID))
+	case reflect.Map:
+		switch dest := db.Statement.Dest.(type) {
+		case map[string]any:
+			dest[field.Name] = tenantID
+		case []map[string]any:
+			for _, row := range dest {
+				row[field.Name] = tenantID
+			}
+		}
+	}
+	p._scopeUpsert(db, tenantID)
+}
+
+// _scopeUpsert restricts the update of an upsert to the rows of the tenant, otherwise the
+// conflicting row of another tenant would be overwritten, e.g. by Save with its primary key.
+func (p *TenantPlugin) _scopeUpsert(db *gorm.DB, tenantID string) {
+	c, ok := db.Statement.Clauses["ON CONFLICT"]
+	if !ok {
+		return
+	}
+	onConflict, ok := c.Expression.(clause.OnConflict)
+	if !ok || onConflict.DoNothing {
+		return
+	}
+	// Only the ON CONFLICT clause of these databases supports a WHERE on the update
+	if name := db.Dialector.Name(); name != "postgres" && name != "sqlite" {
+		_ = db.AddError(ErrTenantUpsert)
+		return
+	}
+	onConflict.Where.Exprs = append(onConflict.Where.Exprs,
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: p.config.Column}, Value: tenantID},
+	)
+	db.Statement.AddClause(onConflict)
+}
+
+// _isGlobalWrite reports whether the statement is an update or delete that gorm rejects
+// with gorm.ErrMissingWhereClause: no conditions, no primary key and no AllowGlobalUpdate.
+func _isGlobalWrite(db *gorm.DB) bool {
+	if _, ok := db.Statement.Clauses["WHERE"]; ok || db.AllowGlobalUpdate {
+		return false
+	}
+	value := db.Statement.ReflectValue
+	if !value.IsValid() || db.Statement.Schema == nil || len(db.Statement.Schema.PrimaryFields) == 0 {
+		return true
+	}
+	_, primaryKeys := schema.GetIdentityFieldValuesMap(db.Statement.Context, value, db.Statement.Schema.PrimaryFields)
+	return len(primaryKeys) == 0
+}
@@ -0,0 +1,121 @@
+package gormutils
+
+import (
+	"context"
+	"errors"
+	"github.com/glebarez/sqlite"
+	"github.com/ppabimanyu/compage/reqctx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"testing"
+)
+
+type tenantUser struct {
+	ID       int
+	TenantID string
+	Name     string
+}
+
+func _dryRunDB(t *testing.T, config *TenantConfig) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(NewTenantPlugin(config)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestTenantPluginColumn(t *testing.T) {
+	db := _dryRunDB(t, nil)
+	ctx := reqctx.WithTenantID(context.Background(), "acme")
+
+	stmt := db.WithContext(ctx).Where("name = ?", "bob").Find(&[]tenantUser{}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, `"tenant_users"."tenant_id" = $2`) || stmt.Vars[1] != "acme" {
+		t.Errorf("query not scoped: %s %v", sql, stmt.Vars)
+	}
+
+	user := tenantUser{Name: "bob", TenantID: "other"}
+	db.WithContext(ctx).Create(&user)
+	if user.TenantID != "acme" {
+		t.Errorf("unexpected tenant on create %q", user.TenantID)
+	}
+
+	err := db.WithContext(ctx).Delete(&tenantUser{}).Error
+	if !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("expected the delete without conditions to be rejected, got %v", err)
+	}
+
+	err = db.Find(&[]tenantUser{}).Error
+	if !errors.Is(err, ErrMissingTenant) {
+		t.Errorf("expected ErrMissingTenant, got %v", err)
+	}
+	if err := db.Scopes(SkipTenant).Find(&[]tenantUser{}).Error; err != nil {
+		t.Errorf("unexpected error with SkipTenant: %v", err)
+	}
+}
+
+func TestTenantPluginSchema(t *testing.T) {
+	db := _dryRunDB(t, &TenantConfig{Mode: SchemaTenantMode})
+
+	ctx := reqctx.WithTenantID(context.Background(), "Acme-EU")
+	stmt := db.WithContext(ctx).Find(&[]tenantUser{}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, `FROM "tenant_acme_eu"."tenant_users"`) {
+		t.Errorf("table not qualified: %s", sql)
+	}
+
+	ctx = reqctx.WithTenantID(context.Background(), `acme"; DROP`)
+	if err := db.WithContext(ctx).Find(&[]tenantUser{}).Error; !errors.Is(err, ErrInvalidTenantSchema) {
+		t.Errorf("expected ErrInvalidTenantSchema, got %v", err)
+	}
+}
+
+func TestTenantPluginSaveOtherTenant(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(NewTenantPlugin(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&tenantUser{}); err != nil {
+		t.Fatal(err)
+	}
+	acme := reqctx.WithTenantID(context.Background(), "acme")
+	other := reqctx.WithTenantID(context.Background(), "other")
+
+	user := tenantUser{Name: "bob"}
+	if err := db.WithContext(acme).Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	// The update finds no row of the tenant, so Save falls back to an upsert
+	if err := db.WithContext(other).Save(&tenantUser{ID: user.ID, Name: "mallory"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var actual tenantUser
+	if err := db.WithContext(acme).First(&actual, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if actual != user {
+		t.Errorf("the row of another tenant was overwritten: %+v", actual)
+	}
+
+	// The upsert still updates the rows of the tenant
+	if err := db.WithContext(acme).Clauses(clause.OnConflict{UpdateAll: true}).Create(&tenantUser{ID: user.ID, Name: "alice"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WithContext(acme).First(&actual, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if actual.Name != "alice" {
+		t.Errorf("expected the upsert to update the row of the tenant, got %+v", actual)
+	}
+}
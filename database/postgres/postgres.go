@@ -20,6 +20,9 @@ type Config struct {
 	MaxOpenConn     int
 	ConnMaxIdleTime time.Duration
 	ConnMaxLifetime time.Duration
+
+	// Plugins are registered on the connection with db.Use, e.g. gormutils.NewTenantPlugin.
+	Plugins []gorm.Plugin
 }
 
 func NewConnection(config *Config) (*gorm.DB, error) {
@@ -62,6 +65,12 @@ func NewConnection(config *Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	for _, plugin := range config.Plugins {
+		if err := db.Use(plugin); err != nil {
+			return nil, err
+		}
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
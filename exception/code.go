@@ -1,17 +1,34 @@
 package exception
 
+// Code is the error code of an Exception. The codes follow the canonical gRPC status codes,
+// see GetHttpCode and GetGRPCCode for their mappings.
 type Code string
 
 const (
-	InvalidParameterCode Code = "INVALID_PARAMETER"
-	InvalidDataCode      Code = "INVALID_DATA"
-	NotFoundCode         Code = "NOT_FOUND"
-	AlreadyExistsCode    Code = "ALREADY_EXISTS"
-	PermissionDeniedCode Code = "PERMISSION_DENIED"
-	UnauthenticatedCode  Code = "UNAUTHENTICATED"
-	InternalErrorCode    Code = "INTERNAL_ERROR"
+	InvalidParameterCode   Code = "INVALID_PARAMETER"
+	InvalidDataCode        Code = "INVALID_DATA"
+	NotFoundCode           Code = "NOT_FOUND"
+	AlreadyExistsCode      Code = "ALREADY_EXISTS"
+	PermissionDeniedCode   Code = "PERMISSION_DENIED"
+	UnauthenticatedCode    Code = "UNAUTHENTICATED"
+	InternalErrorCode      Code = "INTERNAL_ERROR"
+	NotAcceptableCode      Code = "NOT_ACCEPTABLE"
+	ResourceExhaustedCode  Code = "RESOURCE_EXHAUSTED"
+	DeadlineExceededCode   Code = "DEADLINE_EXCEEDED"
+	FailedPreconditionCode Code = "FAILED_PRECONDITION"
+	UnavailableCode        Code = "UNAVAILABLE"
+	UnimplementedCode      Code = "UNIMPLEMENTED"
+	AbortedCode            Code = "ABORTED"
+	CanceledCode           Code = "CANCELED"
+	OutOfRangeCode         Code = "OUT_OF_RANGE"
+	DataLossCode           Code = "DATA_LOSS"
 )
 
 func (e Code) ToString() string {
 	return string(e)
 }
+
+// Error returns the code, so a Code can be the target of errors.Is, see Exception.Is.
+func (e Code) Error() string {
+	return string(e)
+}
@@ -0,0 +1,33 @@
+package exception
+
+import "testing"
+
+func TestCodeMappings(t *testing.T) {
+	tests := []struct {
+		exc  *Exception
+		http int
+		grpc int32
+	}{
+		{InvalidParameter("", nil), 400, 3},
+		{NotFound("", nil), 404, 5},
+		{ResourceExhausted("", nil), 429, 8},
+		{DeadlineExceeded("", nil), 504, 4},
+		{FailedPrecondition("", nil), 400, 9},
+		{Unavailable("", nil), 503, 14},
+		{Unimplemented("", nil), 501, 12},
+		{Aborted("", nil), 409, 10},
+		{Canceled("", nil), 499, 1},
+		{OutOfRange("", nil), 400, 11},
+		{DataLoss("", nil), 500, 15},
+		{Internal("", nil), 500, 13},
+		{_createException("UNKNOWN", "", nil, nil), 500, 13},
+	}
+	for _, tt := range tests {
+		if code := tt.exc.GetHttpCode(); code != tt.http {
+			t.Errorf("%s: expected HTTP status %d, got %d", tt.exc.GetCode(), tt.http, code)
+		}
+		if code := tt.exc.GetGRPCCode(); code != tt.grpc {
+			t.Errorf("%s: expected gRPC code %d, got %d", tt.exc.GetCode(), tt.grpc, code)
+		}
+	}
+}
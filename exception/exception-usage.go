@@ -33,6 +33,69 @@ func Unauthenticated(message string, err error) *Exception {
 	return _createException(UnauthenticatedCode, message, err, nil)
 }
 
+// NotAcceptable creates a new Exception with the NotAcceptableCode error code.
+// To be used when the response cannot be produced in any representation accepted by the client.
+func NotAcceptable(message string, err error) *Exception {
+	return _createException(NotAcceptableCode, message, err, nil)
+}
+
+// ResourceExhausted creates a new Exception with the ResourceExhaustedCode error code.
+// To be used when a quota or a rate limit is exceeded.
+func ResourceExhausted(message string, err error) *Exception {
+	return _createException(ResourceExhaustedCode, message, err, nil)
+}
+
+// DeadlineExceeded creates a new Exception with the DeadlineExceededCode error code.
+// To be used when an operation did not complete in time, e.g. a call to a dependency timed out.
+func DeadlineExceeded(message string, err error) *Exception {
+	return _createException(DeadlineExceededCode, message, err, nil)
+}
+
+// FailedPrecondition creates a new Exception with the FailedPreconditionCode error code.
+// To be used when the system is not in the state required by the operation,
+// e.g. deleting a non-empty folder. The client should not retry until the state is fixed.
+func FailedPrecondition(message string, err error) *Exception {
+	return _createException(FailedPreconditionCode, message, err, nil)
+}
+
+// Unavailable creates a new Exception with the UnavailableCode error code.
+// To be used when the service or one of its dependencies is temporarily unavailable.
+// The client may retry with a backoff.
+func Unavailable(message string, err error) *Exception {
+	return _createException(UnavailableCode, message, err, nil)
+}
+
+// Unimplemented creates a new Exception with the UnimplementedCode error code.
+// To be used when the operation is not implemented or not supported.
+func Unimplemented(message string, err error) *Exception {
+	return _createException(UnimplementedCode, message, err, nil)
+}
+
+// Aborted creates a new Exception with the AbortedCode error code.
+// To be used when the operation was aborted by a concurrency conflict,
+// e.g. an optimistic lock or a transaction failure. The client may retry the whole sequence.
+func Aborted(message string, err error) *Exception {
+	return _createException(AbortedCode, message, err, nil)
+}
+
+// Canceled creates a new Exception with the CanceledCode error code.
+// To be used when the operation was canceled, typically by the client.
+func Canceled(message string, err error) *Exception {
+	return _createException(CanceledCode, message, err, nil)
+}
+
+// OutOfRange creates a new Exception with the OutOfRangeCode error code.
+// To be used when a value is past the valid range, e.g. a page after the last one.
+func OutOfRange(message string, err error) *Exception {
+	return _createException(OutOfRangeCode, message, err, nil)
+}
+
+// DataLoss creates a new Exception with the DataLossCode error code.
+// To be used on unrecoverable data loss or corruption.
+func DataLoss(message string, err error) *Exception {
+	return _createException(DataLossCode, message, err, nil)
+}
+
 // Internal creates a new Exception with the ErrorInternalCode error code.
 // The original error that caused the exception is also included.
 func Internal(message string, err error) *Exception {
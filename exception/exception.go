@@ -1,15 +1,32 @@
 package exception
 
 import (
-	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
 )
 
+// maxStackDepth is the maximum number of frames captured for internal errors.
+const maxStackDepth = 32
+
+// Exception is an error with a Code, a message for the client and the underlying error.
+// It can be returned as an error and extracted from a wrap chain with errors.As:
+//
+//	var exc *exception.Exception
+//	if errors.As(err, &exc) {
+//		status := exc.GetHttpCode()
+//	}
 type Exception struct {
-	code     Code
-	message  string
-	error    error
-	errorMap map[string]string
+	code      Code
+	message   string
+	error     error
+	errorMap  map[string]string
+	metadata  map[string]any
+	retryable bool
+	stack     []uintptr
 }
 
 func (e *Exception) GetError() string {
@@ -34,15 +51,135 @@ func (e *Exception) GetMessage() string {
 	return e.message
 }
 
+// GetDetailError returns an error describing the message and the underlying error,
+// which it wraps.
 func (e *Exception) GetDetailError() error {
-	return errors.New(fmt.Sprintf("msg: %s, error: %v", e.message, e.error))
+	if e.error == nil {
+		return fmt.Errorf("msg: %s, error: %v", e.message, nil)
+	}
+	return fmt.Errorf("msg: %s, error: %w", e.message, e.error)
+}
+
+// Error returns the message followed by the underlying error, if any.
+func (e *Exception) Error() string {
+	if e.error != nil {
+		return e.message + ": " + e.error.Error()
+	}
+	return e.message
+}
+
+// Unwrap returns the underlying error, so errors.Is and errors.As reach it.
+func (e *Exception) Unwrap() error {
+	return e.error
+}
+
+// Is reports whether the exception has the code of the target, an *Exception or a Code:
+//
+//	errors.Is(err, exception.NotFoundCode)
+func (e *Exception) Is(target error) bool {
+	switch t := target.(type) {
+	case *Exception:
+		return t != nil && e.code == t.code
+	case Code:
+		return e.code == t
+	}
+	return false
+}
+
+// WithMetadata returns a copy of the exception with a key/value pair describing its context,
+// e.g. the ID of the entity. The metadata is logged but never sent to the clients.
+// The receiver is left unchanged, so shared exceptions can be annotated per request.
+func (e *Exception) WithMetadata(key string, value any) *Exception {
+	c := *e
+	c.metadata = maps.Clone(e.metadata)
+	if c.metadata == nil {
+		c.metadata = map[string]any{}
+	}
+	c.metadata[key] = value
+	return &c
+}
+
+func (e *Exception) GetMetadata() map[string]any {
+	return e.metadata
+}
+
+// WithRetryable returns a copy of the exception marking whether the operation may succeed
+// when retried. The receiver is left unchanged.
+func (e *Exception) WithRetryable(retryable bool) *Exception {
+	c := *e
+	c.retryable = retryable
+	return &c
+}
+
+// IsRetryable reports whether the operation may succeed when retried. It defaults to true for
+// the UnavailableCode, ResourceExhaustedCode, AbortedCode and DeadlineExceededCode codes.
+func (e *Exception) IsRetryable() bool {
+	return e.retryable
+}
+
+// GetStack returns the stack trace captured when an internal exception was created,
+// with the function, file and line of each frame, or "" for the other codes.
+func (e *Exception) GetStack() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// LogValue implements slog.LogValuer, so logging an exception emits a group with its code,
+// message, error, error map, retryable flag, metadata and stack trace.
+//
+//	slog.ErrorContext(ctx, "Service: Failed to create user", "exception", exc)
+func (e *Exception) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("code", e.code.ToString()),
+		slog.String("message", e.message),
+	}
+	if e.error != nil {
+		attrs = append(attrs, slog.String("error", e.error.Error()))
+	}
+	if len(e.errorMap) > 0 {
+		attrs = append(attrs, slog.Any("error_map", e.errorMap))
+	}
+	if e.retryable {
+		attrs = append(attrs, slog.Bool("retryable", true))
+	}
+	if len(e.metadata) > 0 {
+		metadata := make([]any, 0, len(e.metadata))
+		for _, key := range slices.Sorted(maps.Keys(e.metadata)) {
+			metadata = append(metadata, slog.Any(key, e.metadata[key]))
+		}
+		attrs = append(attrs, slog.Group("metadata", metadata...))
+	}
+	if stack := e.GetStack(); stack != "" {
+		attrs = append(attrs, slog.String("stack", stack))
+	}
+	return slog.GroupValue(attrs...)
 }
 
 func _createException(code Code, message string, err error, errorMap map[string]string) *Exception {
-	return &Exception{
+	e := &Exception{
 		code:     code,
 		message:  message,
 		error:    err,
 		errorMap: errorMap,
 	}
+	switch code {
+	case UnavailableCode, ResourceExhaustedCode, AbortedCode, DeadlineExceededCode:
+		e.retryable = true
+	case InternalErrorCode:
+		// Skip runtime.Callers, _createException and the constructor
+		e.stack = make([]uintptr, maxStackDepth)
+		e.stack = e.stack[:runtime.Callers(3, e.stack)]
+	}
+	return e
 }
@@ -0,0 +1,84 @@
+package exception
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestExceptionError(t *testing.T) {
+	exc := NotFound("User not found", sql.ErrNoRows)
+	err := fmt.Errorf("get user: %w", fmt.Errorf("repository: %w", exc))
+
+	if exc.Error() != "User not found: sql: no rows in result set" {
+		t.Errorf("unexpected message %q", exc.Error())
+	}
+	var target *Exception
+	if !errors.As(err, &target) || target != exc {
+		t.Fatalf("expected the exception to be extracted from the wrap chain")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the underlying error to be reachable")
+	}
+	if !errors.Is(err, NotFoundCode) || !errors.Is(err, NotFound("", nil)) {
+		t.Errorf("expected the exception to match its code")
+	}
+	if errors.Is(err, InternalErrorCode) || errors.Is(err, Internal("", nil)) {
+		t.Errorf("expected the exception not to match another code")
+	}
+	if !errors.Is(exc.GetDetailError(), sql.ErrNoRows) {
+		t.Errorf("expected the detail error to wrap the underlying error")
+	}
+}
+
+func TestExceptionLogValue(t *testing.T) {
+	exc := Internal("Failed to create user", errors.New("connection reset")).
+		WithMetadata("user_id", 42).
+		WithMetadata("email", "bob@example.com")
+
+	if !strings.Contains(exc.GetStack(), "TestExceptionLogValue") {
+		t.Errorf("expected the stack to start at the caller, got %q", exc.GetStack())
+	}
+	if NotFound("", nil).GetStack() != "" {
+		t.Errorf("expected no stack for a client error")
+	}
+	if exc.IsRetryable() || !Unavailable("", nil).IsRetryable() || Unavailable("", nil).WithRetryable(false).IsRetryable() {
+		t.Errorf("unexpected retryable flags")
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "exception", exc)
+	var record struct {
+		Exception map[string]any `json:"exception"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	metadata, _ := record.Exception["metadata"].(map[string]any)
+	if record.Exception["code"] != "INTERNAL_ERROR" || record.Exception["error"] != "connection reset" ||
+		metadata["user_id"] != float64(42) || record.Exception["stack"] == nil {
+		t.Errorf("unexpected log group %v", record.Exception)
+	}
+}
+
+func TestExceptionWithCopies(t *testing.T) {
+	shared := NotFound("User not found", nil).WithMetadata("table", "users")
+
+	first := shared.WithMetadata("user_id", 1)
+	second := shared.WithMetadata("user_id", 2).WithRetryable(true)
+
+	if len(shared.GetMetadata()) != 1 || shared.IsRetryable() {
+		t.Errorf("the shared exception must not be modified, got %v", shared.GetMetadata())
+	}
+	if first.GetMetadata()["user_id"] != 1 || second.GetMetadata()["user_id"] != 2 || first.IsRetryable() {
+		t.Errorf("the copies must not share their metadata, got %v and %v", first.GetMetadata(), second.GetMetadata())
+	}
+	if !errors.Is(first, shared) || first.GetMessage() != shared.GetMessage() {
+		t.Errorf("expected the copy to keep the exception")
+	}
+}
@@ -1,19 +1,38 @@
 package exception
 
+// GetGRPCCode returns the gRPC status code of the exception.
 func (e *Exception) GetGRPCCode() int32 {
 	switch e.code {
-	case InvalidParameterCode, InvalidDataCode:
+	case CanceledCode:
+		return 1
+	case InvalidParameterCode, InvalidDataCode, NotAcceptableCode:
 		return 3
+	case DeadlineExceededCode:
+		return 4
 	case NotFoundCode:
 		return 5
 	case AlreadyExistsCode:
 		return 6
 	case PermissionDeniedCode:
 		return 7
-	case UnauthenticatedCode:
-		return 16
+	case ResourceExhaustedCode:
+		return 8
+	case FailedPreconditionCode:
+		return 9
+	case AbortedCode:
+		return 10
+	case OutOfRangeCode:
+		return 11
+	case UnimplementedCode:
+		return 12
 	case InternalErrorCode:
 		return 13
+	case UnavailableCode:
+		return 14
+	case DataLossCode:
+		return 15
+	case UnauthenticatedCode:
+		return 16
 	default:
 		return 13
 	}
@@ -1,18 +1,33 @@
 package exception
 
+// GetHttpCode returns the HTTP status of the exception, following the mapping of
+// the canonical gRPC codes in google/rpc/code.proto.
 func (e *Exception) GetHttpCode() int {
 	switch e.code {
-	case InvalidParameterCode, InvalidDataCode:
+	case InvalidParameterCode, InvalidDataCode, FailedPreconditionCode, OutOfRangeCode:
 		return 400
 	case NotFoundCode:
 		return 404
-	case AlreadyExistsCode:
+	case AlreadyExistsCode, AbortedCode:
 		return 409
 	case PermissionDeniedCode:
 		return 403
 	case UnauthenticatedCode:
 		return 401
-	case InternalErrorCode:
+	case NotAcceptableCode:
+		return 406
+	case ResourceExhaustedCode:
+		return 429
+	case CanceledCode:
+		// Client Closed Request, the non-standard status used for canceled requests
+		return 499
+	case UnimplementedCode:
+		return 501
+	case UnavailableCode:
+		return 503
+	case DeadlineExceededCode:
+		return 504
+	case InternalErrorCode, DataLossCode:
 		return 500
 	default:
 		return 500
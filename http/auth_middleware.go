@@ -0,0 +1,214 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/jwt"
+	"log/slog"
+	"strings"
+)
+
+type claimsCtxKeyType struct{}
+
+// ClaimsCtxKey is the Locals key under which AuthMiddleware stores the verified claims.
+var ClaimsCtxKey = claimsCtxKeyType{}
+
+type AuthConfig struct {
+	// SecretKey is the key used by jwt.VerifyToken to verify the token.
+	// It is ignored when Verifier is set.
+	SecretKey []byte
+
+	// Verifier verifies a raw token and returns its claims. It is called with the request context,
+	// so it can check revocations, e.g. jwt.TokenManager.VerifyAccessToken which enforces the denylist.
+	// Default is jwt.VerifyToken with SecretKey.
+	Verifier func(ctx context.Context, token string) (map[string]any, error)
+
+	// TokenLookup is a comma separated list of "<source>:<name>" pairs used to extract the token.
+	// Supported sources are header, cookie and query, and the first non-empty value wins.
+	// The "Bearer " prefix is stripped from header values.
+	// Default is "header:Authorization".
+	TokenLookup string
+
+	// SkipPaths is a list of paths which do not require authentication.
+	// A trailing "*" matches any path with the given prefix.
+	SkipPaths []string
+
+	// Skip is an optional function to skip the middleware for a request.
+	Skip func(c *fiber.Ctx) bool
+
+	// RequiredClaims is a list of claims which must be present in every token.
+	RequiredClaims []string
+}
+
+type tokenExtractor func(c *fiber.Ctx) string
+
+/*
+AuthMiddleware is a Fiber middleware which authenticates requests with a JWT.
+
+The middleware performs the following steps:
+1. Skips the request when its path matches SkipPaths or Skip returns true.
+2. Extracts the token according to TokenLookup.
+3. Verifies the token and checks that every claim in RequiredClaims is present.
+4. Stores the claims in Locals under ClaimsCtxKey, so they can be read with GetClaims.
+
+Requests without a valid token are answered with Handler.Unauthorized and a generic message,
+the reason is only logged.
+*/
+func AuthMiddleware(config *AuthConfig) fiber.Handler {
+	if config == nil {
+		panic("config cannot be nil")
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = "header:Authorization"
+	}
+	if config.Verifier == nil {
+		if len(config.SecretKey) == 0 {
+			panic("secret key or verifier must be provided")
+		}
+		config.Verifier = func(_ context.Context, token string) (map[string]any, error) {
+			return jwt.VerifyToken(token, config.SecretKey)
+		}
+	}
+	extractors := _tokenExtractors(config.TokenLookup)
+
+	handler := Handler{}
+	return func(c *fiber.Ctx) error {
+		if _skipAuth(c, config) {
+			return c.Next()
+		}
+
+		var token string
+		for _, extract := range extractors {
+			if token = extract(c); token != "" {
+				break
+			}
+		}
+		if token == "" {
+			return _rejectAuth(c, handler.Unauthorized, "Missing authentication token", errors.New("token not found"))
+		}
+
+		claims, err := config.Verifier(c.UserContext(), token)
+		if err != nil {
+			return _rejectAuth(c, handler.Unauthorized, "Invalid authentication token", err)
+		}
+		for _, claim := range config.RequiredClaims {
+			if _, ok := claims[claim]; !ok {
+				return _rejectAuth(c, handler.Unauthorized, "Invalid authentication token", fmt.Errorf("missing required claim: %s", claim))
+			}
+		}
+
+		c.Locals(ClaimsCtxKey, claims)
+		return c.Next()
+	}
+}
+
+// RequireClaim is a route level middleware which only lets the request through
+// when the authenticated token contains the given claim. When values are given,
+// the claim must be equal to one of them or, for array claims, contain one of them.
+// It must be used after AuthMiddleware, and responds with Handler.Forbidden otherwise.
+func RequireClaim(name string, values ...any) fiber.Handler {
+	handler := Handler{}
+	return func(c *fiber.Ctx) error {
+		claim, ok := GetClaims(c)[name]
+		if !ok {
+			return _rejectAuth(c, handler.Forbidden, "Access denied", fmt.Errorf("missing claim: %s", name))
+		}
+		if len(values) > 0 && !_claimMatches(claim, values) {
+			return _rejectAuth(c, handler.Forbidden, "Access denied", fmt.Errorf("claim %s does not match", name))
+		}
+		return c.Next()
+	}
+}
+
+// GetClaims returns the claims stored by AuthMiddleware, or nil when the request is not authenticated.
+func GetClaims(c *fiber.Ctx) map[string]any {
+	claims, ok := c.Locals(ClaimsCtxKey).(map[string]any)
+	if !ok {
+		return nil
+	}
+	return claims
+}
+
+// _rejectAuth logs the reason of the rejection and responds with a generic message,
+// so clients do not learn which check of the token failed.
+func _rejectAuth(c *fiber.Ctx, respond func(c *fiber.Ctx, msg string, err error) error, msg string, reason error) error {
+	slog.InfoContext(c.UserContext(), "AuthMiddleware: Request rejected", "path", c.Path(), "reason", reason.Error())
+	return respond(c, msg, nil)
+}
+
+func _skipAuth(c *fiber.Ctx, config *AuthConfig) bool {
+	if config.Skip != nil && config.Skip(c) {
+		return true
+	}
+	return _matchPaths(c.Path(), config.SkipPaths)
+}
+
+// _matchPaths reports whether the path is one of the paths, where a trailing "*" matches any
+// path with the given prefix.
+func _matchPaths(path string, paths []string) bool {
+	for _, p := range paths {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+func _tokenExtractors(lookup string) []tokenExtractor {
+	var extractors []tokenExtractor
+	for _, source := range strings.Split(lookup, ",") {
+		parts := strings.SplitN(strings.TrimSpace(source), ":", 2)
+		if len(parts) != 2 {
+			panic(fmt.Sprintf("invalid token lookup: %s", source))
+		}
+		name := strings.TrimSpace(parts[1])
+		switch parts[0] {
+		case "header":
+			extractors = append(extractors, func(c *fiber.Ctx) string {
+				value := c.Get(name)
+				if len(value) > 7 && strings.EqualFold(value[:7], "Bearer ") {
+					return value[7:]
+				}
+				if strings.EqualFold(name, fiber.HeaderAuthorization) {
+					return ""
+				}
+				return value
+			})
+		case "cookie":
+			extractors = append(extractors, func(c *fiber.Ctx) string {
+				return c.Cookies(name)
+			})
+		case "query":
+			extractors = append(extractors, func(c *fiber.Ctx) string {
+				return c.Query(name)
+			})
+		default:
+			panic(fmt.Sprintf("unsupported token lookup source: %s", parts[0]))
+		}
+	}
+	return extractors
+}
+
+func _claimMatches(claim any, values []any) bool {
+	if list, ok := claim.([]any); ok {
+		for _, item := range list {
+			if _claimMatches(item, values) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, value := range values {
+		// Claims decoded from JSON are float64, so compare their string form.
+		if fmt.Sprint(claim) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/jwt"
+	"github.com/redis/go-redis/v9"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("test-secret")
+
+func _authApp(config *AuthConfig) *fiber.App {
+	app := fiber.New()
+	app.Use(AuthMiddleware(config))
+	app.Get("/public", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/me", func(c *fiber.Ctx) error {
+		return c.SendString(GetClaims(c)["sub"].(string))
+	})
+	app.Get("/admin", RequireClaim("roles", "admin"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func _token(t *testing.T, payload map[string]any, exp time.Duration) string {
+	t.Helper()
+	token, err := jwt.GenToken(payload, testSecret, exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	app := _authApp(&AuthConfig{
+		SecretKey:      testSecret,
+		TokenLookup:    "header:Authorization,query:token",
+		SkipPaths:      []string{"/public"},
+		RequiredClaims: []string{"sub"},
+	})
+	valid := _token(t, map[string]any{"sub": "user-1", "roles": []string{"admin"}}, time.Minute)
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		status int
+	}{
+		{"skipped path", "/public", "", fiber.StatusOK},
+		{"missing token", "/me", "", fiber.StatusUnauthorized},
+		{"bearer header", "/me", "Bearer " + valid, fiber.StatusOK},
+		{"query token", "/me?token=" + valid, "", fiber.StatusOK},
+		{"expired token", "/me", "Bearer " + _token(t, map[string]any{"sub": "user-1"}, -time.Minute), fiber.StatusUnauthorized},
+		{"missing required claim", "/me", "Bearer " + _token(t, map[string]any{"name": "bob"}, time.Minute), fiber.StatusUnauthorized},
+		{"wrong signature", "/me", "Bearer " + valid[:len(valid)-2] + "xx", fiber.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.header)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if resp.StatusCode != fiber.StatusUnauthorized {
+				return
+			}
+			var r Response
+			if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+				t.Fatal(err)
+			}
+			if details, _ := r.Error.Details.(string); details != "" || strings.Contains(r.Message, "exp") {
+				t.Errorf("the verification error must not be exposed, got %q %q", r.Message, details)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareTokenManager(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	defer client.Close()
+	ks, _ := jwt.NewKeySet(jwt.NewHMACKey("v1", testSecret))
+	manager, err := jwt.NewTokenManager(&jwt.TokenManagerConfig{Signer: ks, Redis: client})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := _authApp(&AuthConfig{Verifier: manager.VerifyAccessToken})
+
+	pair, err := manager.Issue(context.Background(), map[string]any{"sub": "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func() int {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodGet, "/me", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+pair.AccessToken)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := request(); status != fiber.StatusOK {
+		t.Fatalf("expected status 200, got %d", status)
+	}
+	if err := manager.Revoke(context.Background(), pair.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	if status := request(); status != fiber.StatusUnauthorized {
+		t.Errorf("expected the revoked token to be rejected, got %d", status)
+	}
+}
+
+func TestRequireClaim(t *testing.T) {
+	app := _authApp(&AuthConfig{SecretKey: testSecret})
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		status int
+	}{
+		{"matching array claim", map[string]any{"sub": "1", "roles": []string{"user", "admin"}}, fiber.StatusOK},
+		{"other value", map[string]any{"sub": "1", "roles": []string{"user"}}, fiber.StatusForbidden},
+		{"missing claim", map[string]any{"sub": "1"}, fiber.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(fiber.MethodGet, "/admin", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+_token(t, tt.claims, time.Minute))
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, resp.StatusCode)
+		}
+	}
+}
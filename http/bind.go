@@ -0,0 +1,139 @@
+package http
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/exception"
+	"github.com/ppabimanyu/compage/pagination"
+	"github.com/ppabimanyu/compage/validator"
+	"reflect"
+	"sync"
+)
+
+var (
+	defaultValidator     *validator.Validator
+	defaultValidatorOnce sync.Once
+)
+
+func _defaultValidator() *validator.Validator {
+	defaultValidatorOnce.Do(func() {
+		defaultValidator = validator.NewValidator()
+	})
+	return defaultValidator
+}
+
+/*
+Bind parses the request into a new T and validates it with a default validator.Validator.
+See BindWith for details.
+
+	func (h *UserHandler) Create(c *fiber.Ctx) error {
+		req, exc := http.Bind[CreateUserRequest](c)
+		if exc != nil {
+			return h.Exception(c, exc)
+		}
+		...
+	}
+*/
+func Bind[T any](c *fiber.Ctx) (*T, *exception.Exception) {
+	return BindWith[T](c, _defaultValidator())
+}
+
+/*
+BindWith parses the request into a new T and validates it with the given validator.
+
+The sources are merged in the following order, later sources overriding earlier ones:
+1. The body, according to its Content-Type, using the `json`, `xml` or `form` tags.
+2. The query string, using the `query` tag.
+3. The headers, using the `reqHeader` tag.
+4. The path parameters, using the `params` tag.
+
+The query string, headers and path parameters only bind the fields carrying their tag,
+so clients cannot set untagged fields, e.g. a role or a user ID, through them.
+
+A parsing failure returns an InvalidParameter exception keyed by the failing source,
+and a validation failure returns an InvalidParameter exception with the field errors.
+T must be a struct, otherwise an Internal exception is returned.
+*/
+func BindWith[T any](c *fiber.Ctx, v *validator.Validator) (*T, *exception.Exception) {
+	if typ := reflect.TypeFor[T](); typ.Kind() != reflect.Struct {
+		return nil, exception.Internal("Invalid request type", fmt.Errorf("cannot bind the request into %s, expected a struct", typ))
+	}
+	req := new(T)
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return nil, exception.InvalidParameter("Invalid request body", map[string]string{"body": err.Error()})
+		}
+	}
+	if err := _bindSource(req, "query", c.QueryParser); err != nil {
+		return nil, exception.InvalidParameter("Invalid query parameters", map[string]string{"query": err.Error()})
+	}
+	if err := _bindSource(req, "reqHeader", c.ReqHeaderParser); err != nil {
+		return nil, exception.InvalidParameter("Invalid request headers", map[string]string{"header": err.Error()})
+	}
+	if err := _bindSource(req, "params", c.ParamsParser); err != nil {
+		return nil, exception.InvalidParameter("Invalid path parameters", map[string]string{"params": err.Error()})
+	}
+
+	if v != nil {
+		if errs := v.Struct(req); len(errs) > 0 {
+			return nil, exception.InvalidParameter("Validation failed", errs)
+		}
+	}
+	return req, nil
+}
+
+// _bindSource parses a source into a separate T and only copies the fields carrying the tag
+// of the source into req. Fiber parsers fall back to the field name for untagged fields,
+// which would let any source set them.
+func _bindSource[T any](req *T, tag string, parse func(out any) error) error {
+	src := new(T)
+	// Start from the current values, so the fields missing from the source are kept
+	_copyTaggedFields(reflect.ValueOf(src).Elem(), reflect.ValueOf(req).Elem(), tag)
+	if err := parse(src); err != nil {
+		return err
+	}
+	_copyTaggedFields(reflect.ValueOf(req).Elem(), reflect.ValueOf(src).Elem(), tag)
+	return nil
+}
+
+// _copyTaggedFields copies the exported fields of src carrying the tag into dst,
+// including the fields of embedded structs.
+func _copyTaggedFields(dst, src reflect.Value, tag string) {
+	if dst.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if name := field.Tag.Get(tag); name != "" && name != "-" {
+			dst.Field(i).Set(src.Field(i))
+		} else if field.Anonymous {
+			_copyTaggedFields(dst.Field(i), src.Field(i), tag)
+		}
+	}
+}
+
+/*
+ParsePagination parses the pagination, sorting and filtering query parameters of the request,
+see pagination.Parse. Invalid parameters return an InvalidParameter exception.
+
+	func (h *UserHandler) List(c *fiber.Ctx) error {
+		req, exc := http.ParsePagination(c, userPagination)
+		if exc != nil {
+			return h.Exception(c, exc)
+		}
+		users, meta, err := gormutils.FindPage[User](h.db, req)
+		...
+		return h.Paginated(c, users, meta)
+	}
+*/
+func ParsePagination(c *fiber.Ctx, config *pagination.Config) (*pagination.Request, *exception.Exception) {
+	req, errs := pagination.Parse(c.Queries(), config)
+	if errs != nil {
+		return nil, exception.InvalidParameter("Invalid pagination parameters", errs)
+	}
+	return req, nil
+}
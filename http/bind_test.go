@@ -0,0 +1,106 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/exception"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bindRequest struct {
+	ID     int    `params:"id"`
+	Name   string `json:"name" query:"name" validate:"required"`
+	Page   int    `json:"page" query:"page"`
+	Tenant string `reqHeader:"X-Tenant"`
+	Role   string `json:"-"`
+	UserID string `json:"user_id"`
+}
+
+func _bindApp(bound *bindRequest) *fiber.App {
+	app := fiber.New()
+	app.Post("/users/:id", func(c *fiber.Ctx) error {
+		req, exc := Bind[bindRequest](c)
+		if exc != nil {
+			return c.Status(exc.GetHttpCode()).JSON(exc.GetErrorMap())
+		}
+		*bound = *req
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func TestBind(t *testing.T) {
+	req := httptest.NewRequest(fiber.MethodPost, "/users/7?name=query&role=admin&userid=victim&Role=admin", strings.NewReader(`{"name":"body","page":2,"user_id":"u1"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("Role", "admin")
+	req.Header.Set("Userid", "victim")
+	var actual bindRequest
+	resp, err := _bindApp(&actual).Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	// Sources only bind the fields carrying their tag, later sources overriding earlier ones
+	expected := bindRequest{ID: 7, Name: "query", Page: 2, Tenant: "acme", UserID: "u1"}
+	if actual != expected {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestBindRejections(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		key    string
+	}{
+		{"invalid body", "/users/7", `{"name":`, "body"},
+		{"invalid query", "/users/7?page=abc", `{"name":"body"}`, "query"},
+		{"invalid path parameter", "/users/abc", `{"name":"body"}`, "params"},
+		{"validation failure", "/users/7", `{"page":1}`, "Name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := _bindApp(&bindRequest{}).Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != exception.InvalidParameter("", nil).GetHttpCode() {
+				t.Fatalf("expected status 400, got %d", resp.StatusCode)
+			}
+			var errs map[string]string
+			if err := json.NewDecoder(resp.Body).Decode(&errs); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := errs[tt.key]; !ok {
+				t.Errorf("expected an error for %s, got %v", tt.key, errs)
+			}
+		})
+	}
+}
+
+func TestBindNonStruct(t *testing.T) {
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		if _, exc := Bind[map[string]any](c); exc != nil {
+			return c.SendStatus(exc.GetHttpCode())
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader(`{"name":"body"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", resp.StatusCode)
+	}
+}
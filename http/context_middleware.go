@@ -3,44 +3,76 @@ package http
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 /*
-ContextMiddleware is a Gin middleware function that sets various context values
-for each incoming HTTP request. These values include request ID, trace ID, tenant ID,
+ContextMiddleware is a Fiber middleware function that sets various context values
+for each incoming HTTP request. These values include request ID, trace ID,
 host, request IP, and language. It ensures that these values are available in the
 request context for downstream handlers.
 
 The middleware performs the following steps:
-1. Retrieves the `X-Request-Id` header. If not present, generates a new UUID.
-2. Retrieves the `X-Trace-Id` header. If not present, uses the request ID as the trace ID.
-3. Retrieves the `X-Tenant-Id` header and sets it if present.
-4. Sets the host, client IP, and `Accept-Language` header values in the context.
-5. Proceeds to the next middleware or handler in the chain.
+ 1. Retrieves the `X-Request-Id` header. If not present, uses the ID stored under RequestIDCtxKey
+    by the Fiber requestid middleware or generates a new UUID.
+ 2. Extracts the `traceparent`, `tracestate` and `baggage` headers with the global OpenTelemetry
+    propagator and starts a server span with the HTTP semantic convention attributes.
+    Without `traceparent`, a legacy `X-Trace-Id` header holding a 32 hex characters ID (or a UUID)
+    is used as the trace ID. The trace ID of the span is stored in the context; when no tracer
+    provider is registered, the `X-Trace-Id` header or the request ID is stored instead.
+ 3. Sets the host, client IP, and `Accept-Language` header values in the context.
+ 4. Stores every value in the Fiber Locals and in c.UserContext(), under the typed keys of
+    the reqctx package, so reqctx.RequestIDFrom(ctx) and friends work with either context.
+ 5. Proceeds to the next middleware or handler in the chain, with c.UserContext() carrying the span,
+    and ends the span with the route template and the response status.
+
+The tenant ID is not taken from the unverified `X-Tenant-Id` header, it is resolved by TenantMiddleware.
 */
 func ContextMiddleware() fiber.Handler {
+	tracer := otel.Tracer(TracerName)
+	propagator := otel.GetTextMapPropagator()
 	return func(c *fiber.Ctx) error {
 		requestID := c.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID, _ = c.Locals(RequestIDCtxKey).(string)
+		}
 		if requestID == "" {
 			requestID = uuid.New().String()
 		}
-		c.Locals(RequestIDCtxKey, requestID)
+		// Downstream middlewares reading the header, like the logger, see the same request ID
+		c.Request().Header.Set(RequestIDHeader, requestID)
+		_setCtxValue(c, RequestIDCtxKey, requestID)
 
-		traceID := c.Get(TraceIDHeader)
-		if traceID == "" {
-			traceID = requestID
+		ctx := propagator.Extract(c.UserContext(), headerCarrier{c})
+		legacyTraceID := c.Get(TraceIDHeader)
+		if !trace.SpanContextFromContext(ctx).IsValid() && legacyTraceID != "" {
+			if parent, ok := _legacySpanContext(legacyTraceID); ok {
+				ctx = trace.ContextWithRemoteSpanContext(ctx, parent)
+			}
 		}
-		c.Locals(TraceIDCtxKey, traceID)
+		ctx, span := tracer.Start(ctx, _httpMethod(c),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(_serverSpanAttributes(c)...),
+		)
+		c.SetUserContext(ctx)
 
-		tenantID := c.Get(TenantIDHeader)
-		if tenantID != "" {
-			c.Locals(TenantIDCtxKey, tenantID)
+		// Without a tracer provider the span is not valid, keep the legacy trace ID
+		traceID := legacyTraceID
+		if spanContext := span.SpanContext(); spanContext.IsValid() {
+			traceID = spanContext.TraceID().String()
+		}
+		if traceID == "" {
+			traceID = requestID
 		}
+		_setCtxValue(c, TraceIDCtxKey, traceID)
 
-		c.Locals(HostCtxKey, c.Hostname())
-		c.Locals(RequestIPCtxKey, c.IP())
-		c.Locals(LangCtxKey, c.Get(LangHeader))
+		_setCtxValue(c, HostCtxKey, c.Hostname())
+		_setCtxValue(c, RequestIPCtxKey, c.IP())
+		_setCtxValue(c, LangCtxKey, c.Get(LangHeader))
 
-		return c.Next()
+		err := c.Next()
+		_endServerSpan(c, span, err)
+		return err
 	}
 }
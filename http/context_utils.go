@@ -2,15 +2,18 @@ package http
 
 import (
 	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/reqctx"
 )
 
-var (
-	RequestIDCtxKey = "request_id"
-	TraceIDCtxKey   = "trace_id"
-	TenantIDCtxKey  = "tenant_id"
-	HostCtxKey      = "host"
-	RequestIPCtxKey = "request_ip"
-	LangCtxKey      = "lang"
+// The context keys of the request metadata, see the reqctx package for the typed getters.
+const (
+	RequestIDCtxKey = reqctx.RequestIDKey
+	TraceIDCtxKey   = reqctx.TraceIDKey
+	TenantIDCtxKey  = reqctx.TenantIDKey
+	HostCtxKey      = reqctx.HostKey
+	RequestIPCtxKey = reqctx.RequestIPKey
+	LangCtxKey      = reqctx.LangKey
 )
 
 var (
@@ -20,10 +23,19 @@ var (
 	LangHeader      = "Accept-Language"
 )
 
-func GetCtxValueStr(c context.Context, key string) string {
+// GetCtxValueStr returns the string stored in the context under key, or "" when it is not set.
+// Prefer the typed getters of the reqctx package, e.g. reqctx.RequestIDFrom(ctx).
+func GetCtxValueStr(c context.Context, key any) string {
 	value, ok := c.Value(key).(string)
 	if !ok {
 		return ""
 	}
 	return value
 }
+
+// _setCtxValue stores the value under key both in the Fiber Locals, read through c.Context(),
+// and in c.UserContext(), so it reaches the services called with either context.
+func _setCtxValue(c *fiber.Ctx, key any, value string) {
+	c.Locals(key, value)
+	c.SetUserContext(context.WithValue(c.UserContext(), key, value))
+}
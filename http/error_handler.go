@@ -3,23 +3,74 @@ package http
 import (
 	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/ppabimanyu/compage/exception"
 	"log/slog"
+	"strings"
 )
 
+/*
+ErrorHandler is the Fiber error handler rendering the errors returned by the handlers:
+  - an *exception.Exception, even wrapped, is rendered with its status, as Handler.Exception does.
+  - a *fiber.Error is rendered with its status, and an error code derived from it, e.g. "NOT_FOUND".
+  - any other error is rendered as a 500 Internal Server Error, like an exception.Internal.
+
+Server errors are logged with the exception, its metadata and stack trace, and the request.
+Only their code and message are sent to the client, never the underlying error, like
+Handler.Exception does.
+*/
 func ErrorHandler(dataType ...string) fiber.ErrorHandler {
 	handler := Handler{}
 	return func(c *fiber.Ctx, err error) error {
-		code := fiber.StatusInternalServerError
-		var e *fiber.Error
-		if errors.As(err, &e) {
-			code = e.Code
+		var exc *exception.Exception
+		if errors.As(err, &exc) {
+			return handler._exception(c, exc, err)
 		}
 
-		if code == fiber.StatusInternalServerError {
-			slog.Error("ErrorHandler: Failed to handle error", "error", err)
-			return handler.InternalServerError(c, "Internal Server Error", err)
-		} else {
-			return handler.BadRequest(c, err.Error(), err)
+		var e *fiber.Error
+		if !errors.As(err, &e) || e.Code == fiber.StatusInternalServerError {
+			return handler._exception(c, exception.Internal("Internal Server Error", err), err)
 		}
+		return handler.ReturnByAccept(c, &Response{
+			StatusCode: e.Code,
+			Message:    e.Message,
+			Error: &Error{
+				Code:    _statusErrorCode(e.Code),
+				Details: err.Error(),
+			},
+		})
+	}
+}
+
+// _logServerError logs the exception with its context when it is a server error, as
+// _exceptionResponse only sends its code and message. err is the error returned by the
+// handler, which may wrap the exception.
+func _logServerError(c *fiber.Ctx, exc *exception.Exception, err error) {
+	if exc.GetHttpCode() < fiber.StatusInternalServerError {
+		return
+	}
+	slog.ErrorContext(c.UserContext(), "ErrorHandler: Failed to handle request",
+		"method", c.Method(),
+		"path", c.Path(),
+		"error", err.Error(),
+		"exception", exc,
+	)
+}
+
+// _statusErrorCode returns the error code of a status, e.g. "METHOD_NOT_ALLOWED".
+func _statusErrorCode(status int) string {
+	return strings.ToUpper(strings.ReplaceAll(utils.StatusMessage(status), " ", "_"))
+}
+
+// _errorStatus returns the status the ErrorHandler renders the error with.
+func _errorStatus(err error) int {
+	var exc *exception.Exception
+	if errors.As(err, &exc) {
+		return exc.GetHttpCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
 	}
+	return fiber.StatusInternalServerError
 }
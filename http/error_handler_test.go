@@ -0,0 +1,83 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/exception"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler()})
+	app.Get("/exception", func(c *fiber.Ctx) error {
+		return fmt.Errorf("service: %w", exception.ResourceExhausted("Quota exceeded", nil))
+	})
+	app.Get("/error", func(c *fiber.Ctx) error {
+		return fmt.Errorf("password=secret")
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{"/exception", fiber.StatusTooManyRequests, exception.ResourceExhaustedCode.ToString()},
+		{"/error", fiber.StatusInternalServerError, exception.InternalErrorCode.ToString()},
+		{"/missing", fiber.StatusNotFound, "NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r Response
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.status || r.Error == nil || r.Error.Code != tt.code {
+			t.Errorf("%s: expected status %d and code %s, got %d and %+v", tt.path, tt.status, tt.code, resp.StatusCode, r.Error)
+		}
+		if tt.status == fiber.StatusInternalServerError && r.Error != nil && r.Error.Details != nil {
+			t.Errorf("%s: the internal error must not be sent to the client, got %v", tt.path, r.Error.Details)
+		}
+	}
+}
+
+func TestHandlerExceptionHidesServerErrors(t *testing.T) {
+	handler := Handler{}
+	app := fiber.New()
+	app.Get("/internal", func(c *fiber.Ctx) error {
+		return handler.InternalServerError(c, "Failed to create user", fmt.Errorf("password=secret"))
+	})
+	app.Get("/internal-json", func(c *fiber.Ctx) error {
+		return handler.ExceptionJSON(c, exception.Unavailable("Service unavailable", fmt.Errorf("dial tcp 10.0.0.1:5432")))
+	})
+	app.Get("/not-found", func(c *fiber.Ctx) error {
+		return handler.NotFound(c, "User not found", fmt.Errorf("no rows"))
+	})
+
+	tests := []struct {
+		path    string
+		status  int
+		details any
+	}{
+		{"/internal", fiber.StatusInternalServerError, nil},
+		{"/internal-json", fiber.StatusServiceUnavailable, nil},
+		{"/not-found", fiber.StatusNotFound, "no rows"},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r Response
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.status || r.Error == nil || r.Error.Details != tt.details {
+			t.Errorf("%s: expected status %d and details %v, got %d and %+v", tt.path, tt.status, tt.details, resp.StatusCode, r.Error)
+		}
+	}
+}
@@ -1,46 +1,122 @@
 package http
 
 import (
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/ppabimanyu/compage/exception"
+	"github.com/ppabimanyu/compage/pagination"
+	"github.com/ppabimanyu/compage/reqctx"
 	"strings"
 )
 
 // Handler provides utility methods for handling JSON and XML responses in a Gin application.
 // It includes methods for sending success responses, error responses, and handling exceptions.
 type Handler struct {
+	// ErrorFormat overrides the error format selected for the server by ErrorFormatMiddleware.
+	ErrorFormat ErrorFormat
 }
 
 // JSON sends a JSON response with the given response object.
 // It also sets the RequestID in the response.
+// Error responses are sent as application/problem+json when the Problem format is selected
+// or the Accept header prefers it.
 //
 // Parameters:
 // - c: The Fiber context.
 // - r: The response object to send.
 func (h *Handler) JSON(c *fiber.Ctx, r *Response) error {
-	r.RequestID = GetCtxValueStr(c.Context(), RequestIDCtxKey)
+	return h._json(c, r, _negotiate(c, MIMEApplicationJSON, MIMEProblemJSON))
+}
+
+// _json sends the response as JSON, or as a Problem when mime, the negotiated media type, asks for one.
+func (h *Handler) _json(c *fiber.Ctx, r *Response, mime string) error {
+	if h._useProblem(c, r, mime) {
+		return h.ProblemJSON(c, r)
+	}
+	r.RequestID = reqctx.RequestIDFrom(c.Context())
 	return c.Status(r.StatusCode).JSON(r)
 }
 
 // XML sends an XML response with the given response object.
 // It also sets the RequestID in the response.
+// Error responses are sent as application/problem+xml when the Problem format is selected
+// or the Accept header prefers it.
 //
 // Parameters:
 // - c: The Fiber context.
 // - r: The response object to send.
 func (h *Handler) XML(c *fiber.Ctx, r *Response) error {
-	r.RequestID = GetCtxValueStr(c.Context(), RequestIDCtxKey)
+	return h._xml(c, r, _negotiate(c, MIMEApplicationXML, MIMEProblemXML))
+}
+
+// _xml sends the response as XML, or as a Problem when mime, the negotiated media type, asks for one.
+func (h *Handler) _xml(c *fiber.Ctx, r *Response, mime string) error {
+	if h._useProblem(c, r, mime) {
+		return h.ProblemXML(c, r)
+	}
+	r.RequestID = reqctx.RequestIDFrom(c.Context())
 	return c.Status(r.StatusCode).XML(r)
 }
 
-func (h *Handler) ReturnByAccept(c *fiber.Ctx, r *Response) error {
-	switch c.Get("Accept") {
-	case "application/json":
+// Encode sends the response with the encoder registered for the media type with RegisterEncoder.
+// It falls back to JSON when no encoder is registered for the media type.
+//
+// Parameters:
+// - c: The Fiber context.
+// - mime: The media type of the response.
+// - r: The response object to send.
+func (h *Handler) Encode(c *fiber.Ctx, mime string, r *Response) error {
+	encode, ok := _encoder(mime)
+	if !ok {
 		return h.JSON(c, r)
-	case "application/xml":
-		return h.XML(c, r)
+	}
+	r.RequestID = reqctx.RequestIDFrom(c.Context())
+	var body any = r
+	if h._useProblem(c, r, mime) {
+		body = _problemResponse(c, r)
+	}
+	raw, err := encode(body)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, mime)
+	return c.Status(r.StatusCode).Send(raw)
+}
+
+// ReturnByAccept sends the response in the representation preferred by the Accept header,
+// see NegotiateContentType. Error responses may also be negotiated as RFC 7807 problems,
+// and are only rendered as problems when a problem media type wins the negotiation.
+// When no representation is acceptable, a 406 Not Acceptable exception is sent as JSON,
+// except for error responses which are sent as JSON unchanged.
+//
+// Parameters:
+// - c: The Fiber context.
+// - r: The response object to send.
+func (h *Handler) ReturnByAccept(c *fiber.Ctx, r *Response) error {
+	offers := _encoderMIMEs()
+	if r.Error != nil {
+		offers = append(offers, MIMEProblemJSON, MIMEProblemXML)
+	}
+
+	accept := c.Get(fiber.HeaderAccept)
+	mime, ok := NegotiateContentType(accept, offers...)
+	if !ok {
+		if r.Error != nil {
+			return h._json(c, r, MIMEApplicationJSON)
+		}
+		return h.JSON(c, _exceptionResponse(exception.NotAcceptable(
+			"Not Acceptable",
+			fmt.Errorf("none of the accepted media types is supported: %s", accept),
+		)))
+	}
+
+	switch mime {
+	case MIMEApplicationJSON, MIMEProblemJSON:
+		return h._json(c, r, mime)
+	case MIMEApplicationXML, MIMEProblemXML:
+		return h._xml(c, r, mime)
 	default:
-		return h.JSON(c, r)
+		return h.Encode(c, mime, r)
 	}
 }
 
@@ -137,11 +213,48 @@ func (h *Handler) Data(c *fiber.Ctx, data any) error {
 	return h.ReturnByAccept(c, r)
 }
 
+func _paginatedResponse(data any, meta *pagination.Meta) *Response {
+	r := _dataResponse(data)
+	r.Meta = meta
+	return r
+}
+
+// PaginatedJSON sends a JSON response with a 200 status code, the provided page of data and its meta block.
+//
+// Parameters:
+// - c: The Fiber context.
+// - data: The page of data to include in the response.
+// - meta: The pagination meta of the page.
+func (h *Handler) PaginatedJSON(c *fiber.Ctx, data any, meta *pagination.Meta) error {
+	r := _paginatedResponse(data, meta)
+	return h.JSON(c, r)
+}
+
+// PaginatedXML sends an XML response with a 200 status code, the provided page of data and its meta block.
+//
+// Parameters:
+// - c: The Fiber context.
+// - data: The page of data to include in the response.
+// - meta: The pagination meta of the page.
+func (h *Handler) PaginatedXML(c *fiber.Ctx, data any, meta *pagination.Meta) error {
+	r := _paginatedResponse(data, meta)
+	return h.XML(c, r)
+}
+
+func (h *Handler) Paginated(c *fiber.Ctx, data any, meta *pagination.Meta) error {
+	r := _paginatedResponse(data, meta)
+	return h.ReturnByAccept(c, r)
+}
+
+// _exceptionResponse renders the exception. The underlying error of server errors is never
+// sent to the client, only their code and message; they are logged with _logServerError instead.
 func _exceptionResponse(exc *exception.Exception) *Response {
 	var detailErr any
-	if exc.GetCode() == exception.InvalidParameterCode {
+	switch {
+	case exc.GetHttpCode() >= fiber.StatusInternalServerError:
+	case exc.GetCode() == exception.InvalidParameterCode:
 		detailErr = exc.GetErrorMap()
-	} else {
+	default:
 		detailErr = exc.GetError()
 	}
 	r := &Response{
@@ -161,6 +274,7 @@ func _exceptionResponse(exc *exception.Exception) *Response {
 // - c: The Fiber context.
 // - exc: The exception to handle.
 func (h *Handler) ExceptionJSON(c *fiber.Ctx, exc *exception.Exception) error {
+	_logServerError(c, exc, exc)
 	r := _exceptionResponse(exc)
 	return h.JSON(c, r)
 }
@@ -171,11 +285,19 @@ func (h *Handler) ExceptionJSON(c *fiber.Ctx, exc *exception.Exception) error {
 // - c: The Fiber context.
 // - exc: The exception to handle.
 func (h *Handler) ExceptionXML(c *fiber.Ctx, exc *exception.Exception) error {
+	_logServerError(c, exc, exc)
 	r := _exceptionResponse(exc)
 	return h.XML(c, r)
 }
 
 func (h *Handler) Exception(c *fiber.Ctx, exc *exception.Exception) error {
+	return h._exception(c, exc, exc)
+}
+
+// _exception logs the exception when it is a server error and sends it in the representation
+// preferred by the Accept header. err is the error which carried the exception.
+func (h *Handler) _exception(c *fiber.Ctx, exc *exception.Exception, err error) error {
+	_logServerError(c, exc, err)
 	r := _exceptionResponse(exc)
 	return h.ReturnByAccept(c, r)
 }
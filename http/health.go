@@ -0,0 +1,277 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/exception"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// HealthChecker checks a single dependency of the service.
+// Check returns nil when the dependency is healthy.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type healthCheckerFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (h *healthCheckerFunc) Name() string {
+	return h.name
+}
+
+func (h *healthCheckerFunc) Check(ctx context.Context) error {
+	return h.fn(ctx)
+}
+
+// NewHealthChecker creates a HealthChecker from a plain function.
+//
+// Parameters:
+// - name: The name reported in the health response.
+// - fn: The function performing the check.
+func NewHealthChecker(name string, fn func(ctx context.Context) error) HealthChecker {
+	return &healthCheckerFunc{name: name, fn: fn}
+}
+
+type HealthConfig struct {
+	// LivenessPath is the path of the liveness endpoint.
+	// Default is "/healthz".
+	LivenessPath string
+
+	// ReadinessPath is the path of the readiness endpoint.
+	// Default is "/readyz".
+	ReadinessPath string
+
+	// Timeout is the maximum duration of a single check.
+	// Default is 2 seconds.
+	Timeout time.Duration
+
+	// CacheTTL is how long a check result is reused before the check runs again.
+	// It protects dependencies from aggressive probing. Default is 5 seconds.
+	CacheTTL time.Duration
+
+	// ExposeErrors includes the error of the failed checks in the responses.
+	// The errors may reveal hosts, DSNs or broker addresses, they are always logged.
+	// Default is false, only the status of each check is returned.
+	ExposeErrors bool
+}
+
+type HealthStatus string
+
+const (
+	HealthStatusUp   HealthStatus = "UP"
+	HealthStatusDown HealthStatus = "DOWN"
+)
+
+type HealthCheckResult struct {
+	Name      string       `json:"name" xml:"name"`
+	Status    HealthStatus `json:"status" xml:"status"`
+	Error     string       `json:"error,omitempty" xml:"error,omitempty"`
+	Duration  string       `json:"duration" xml:"duration"`
+	CheckedAt time.Time    `json:"checked_at" xml:"checked_at"`
+}
+
+type HealthReport struct {
+	Status HealthStatus         `json:"status" xml:"status"`
+	Checks []*HealthCheckResult `json:"checks" xml:"checks>check"`
+}
+
+// Health serves the liveness and readiness endpoints of a service.
+// Liveness checks should only fail when the process must be restarted,
+// readiness checks should fail when the service cannot serve traffic.
+type Health struct {
+	config    *HealthConfig
+	mu        sync.RWMutex
+	liveness  []HealthChecker
+	readiness []HealthChecker
+	cache     map[string]*HealthCheckResult
+	ready     bool
+}
+
+func NewHealth(config *HealthConfig) *Health {
+	if config == nil {
+		config = &HealthConfig{}
+	}
+	if config.LivenessPath == "" {
+		config.LivenessPath = "/healthz"
+	}
+	if config.ReadinessPath == "" {
+		config.ReadinessPath = "/readyz"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 2 * time.Second
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = 5 * time.Second
+	}
+	return &Health{
+		config: config,
+		cache:  make(map[string]*HealthCheckResult),
+		ready:  true,
+	}
+}
+
+// AddLivenessCheck registers checkers evaluated by the liveness endpoint.
+func (h *Health) AddLivenessCheck(checkers ...HealthChecker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness = append(h.liveness, checkers...)
+}
+
+// AddReadinessCheck registers checkers evaluated by the readiness endpoint.
+func (h *Health) AddReadinessCheck(checkers ...HealthChecker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness = append(h.readiness, checkers...)
+}
+
+// SetReady forces the readiness endpoint to report DOWN when ready is false,
+// regardless of the registered checks. It is used to stop receiving traffic
+// while the server is shutting down.
+func (h *Health) SetReady(ready bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ready = ready
+}
+
+// Mount registers the liveness and readiness endpoints on the given router.
+func (h *Health) Mount(router fiber.Router) {
+	router.Get(h.config.LivenessPath, h.LivenessHandler())
+	router.Get(h.config.ReadinessPath, h.ReadinessHandler())
+}
+
+func (h *Health) LivenessHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		h.mu.RLock()
+		checkers := h.liveness
+		h.mu.RUnlock()
+		return h._respond(c, h.Run(c.UserContext(), checkers...))
+	}
+}
+
+func (h *Health) ReadinessHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		h.mu.RLock()
+		checkers := h.readiness
+		ready := h.ready
+		h.mu.RUnlock()
+		report := h.Run(c.UserContext(), checkers...)
+		if !ready {
+			report.Status = HealthStatusDown
+		}
+		return h._respond(c, report)
+	}
+}
+
+// Run executes the given checkers concurrently and aggregates their results.
+// Results younger than HealthConfig.CacheTTL are reused instead of running the check again,
+// they are cached by checker name so the checkers do not need to be comparable.
+func (h *Health) Run(ctx context.Context, checkers ...HealthChecker) *HealthReport {
+	report := &HealthReport{
+		Status: HealthStatusUp,
+		Checks: make([]*HealthCheckResult, len(checkers)),
+	}
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = h._check(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != HealthStatusUp {
+			report.Status = HealthStatusDown
+		}
+	}
+	return report
+}
+
+func (h *Health) _check(ctx context.Context, checker HealthChecker) *HealthCheckResult {
+	h.mu.RLock()
+	cached, ok := h.cache[checker.Name()]
+	h.mu.RUnlock()
+	if ok && time.Since(cached.CheckedAt) < h.config.CacheTTL {
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = errors.New("check timed out")
+	}
+
+	result := &HealthCheckResult{
+		Name:      checker.Name(),
+		Status:    HealthStatusUp,
+		Duration:  time.Since(start).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Health: Check failed", "check", checker.Name(), "error", err.Error())
+		result.Status = HealthStatusDown
+		result.Error = err.Error()
+	}
+
+	h.mu.Lock()
+	h.cache[checker.Name()] = result
+	h.mu.Unlock()
+	return result
+}
+
+func (h *Health) _respond(c *fiber.Ctx, report *HealthReport) error {
+	if !h.config.ExposeErrors {
+		report = _withoutErrors(report)
+	}
+	handler := Handler{}
+	r := &Response{
+		StatusCode: fiber.StatusOK,
+		Message:    string(report.Status),
+		Data:       report,
+	}
+	if report.Status != HealthStatusUp {
+		r.StatusCode = fiber.StatusServiceUnavailable
+		r.Error = &Error{
+			Code:    exception.UnavailableCode.ToString(),
+			Details: "one or more health checks failed",
+		}
+	}
+	return handler.ReturnByAccept(c, r)
+}
+
+// _withoutErrors returns a copy of the report without the errors of the checks,
+// leaving the cached results untouched.
+func _withoutErrors(report *HealthReport) *HealthReport {
+	safe := &HealthReport{
+		Status: report.Status,
+		Checks: make([]*HealthCheckResult, len(report.Checks)),
+	}
+	for i, result := range report.Checks {
+		check := *result
+		check.Error = ""
+		safe.Checks[i] = &check
+	}
+	return safe
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"context"
+	"github.com/ppabimanyu/compage/msgbroker/kafka"
+	"github.com/ppabimanyu/compage/msgbroker/rabbitmq"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// GormHealthChecker checks a database connection created by
+// postgres.NewConnection or sqlserver.NewConnection by pinging it.
+func GormHealthChecker(name string, db *gorm.DB) HealthChecker {
+	return NewHealthChecker(name, func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+}
+
+// RedisHealthChecker checks a client created by redis.NewConnection by sending a PING.
+func RedisHealthChecker(name string, client *redis.Client) HealthChecker {
+	return NewHealthChecker(name, func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+}
+
+// KafkaHealthChecker checks that at least one broker of the dealer is reachable.
+func KafkaHealthChecker(name string, dealer *kafka.Dealer) HealthChecker {
+	return NewHealthChecker(name, dealer.Ping)
+}
+
+// RabbitMQHealthChecker checks that the RabbitMQ server of the dealer accepts connections.
+func RabbitMQHealthChecker(name string, dealer *rabbitmq.Dealer) HealthChecker {
+	return NewHealthChecker(name, dealer.Ping)
+}
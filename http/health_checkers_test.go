@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/ppabimanyu/compage/msgbroker/kafka"
+	"github.com/ppabimanyu/compage/msgbroker/rabbitmq"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// _closedPort returns a local port nothing listens on.
+func _closedPort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return port
+}
+
+func _checkContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestGormHealthChecker(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := GormHealthChecker("database", db)
+	if err := checker.Check(_checkContext(t)); err != nil {
+		t.Errorf("expected the database to be up, got %v", err)
+	}
+
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+	if err := checker.Check(_checkContext(t)); err == nil {
+		t.Errorf("expected the closed database to be down")
+	}
+}
+
+func TestRedisHealthChecker(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	checker := RedisHealthChecker("cache", client)
+	if err := checker.Check(_checkContext(t)); err != nil {
+		t.Errorf("expected redis to be up, got %v", err)
+	}
+	server.Close()
+	if err := checker.Check(_checkContext(t)); err == nil {
+		t.Errorf("expected the stopped redis to be down")
+	}
+}
+
+func TestBrokerHealthCheckersDown(t *testing.T) {
+	kafkaChecker := KafkaHealthChecker("kafka", kafka.NewDealer(&kafka.Config{
+		Brokers: []string{"127.0.0.1:" + strconv.Itoa(_closedPort(t))},
+	}))
+	if err := kafkaChecker.Check(_checkContext(t)); err == nil {
+		t.Errorf("expected the unreachable kafka broker to be down")
+	}
+
+	rabbitChecker := RabbitMQHealthChecker("rabbitmq", rabbitmq.NewDealer(&rabbitmq.Config{
+		Host: "127.0.0.1",
+		Port: _closedPort(t),
+	}))
+	if err := rabbitChecker.Check(_checkContext(t)); err == nil {
+		t.Errorf("expected the unreachable rabbitmq server to be down")
+	}
+}
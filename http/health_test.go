@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"net/http/httptest"
+	"testing"
+)
+
+func _readinessReport(t *testing.T, config *HealthConfig) (int, *HealthReport) {
+	t.Helper()
+	health := NewHealth(config)
+	health.AddReadinessCheck(
+		NewHealthChecker("cache", func(ctx context.Context) error { return nil }),
+		NewHealthChecker("database", func(ctx context.Context) error {
+			return errors.New("dial tcp db.internal:5432: connection refused")
+		}),
+	)
+	app := fiber.New()
+	health.Mount(app)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/readyz", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r struct {
+		Data *HealthReport `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, r.Data
+}
+
+func TestHealthReadinessHidesErrors(t *testing.T) {
+	status, report := _readinessReport(t, nil)
+	if status != fiber.StatusServiceUnavailable || report.Status != HealthStatusDown {
+		t.Fatalf("expected the readiness to be down, got %d %s", status, report.Status)
+	}
+	if report.Checks[0].Status != HealthStatusUp || report.Checks[1].Status != HealthStatusDown {
+		t.Errorf("unexpected check statuses %+v %+v", report.Checks[0], report.Checks[1])
+	}
+	if report.Checks[1].Error != "" {
+		t.Errorf("the check error must not be exposed, got %q", report.Checks[1].Error)
+	}
+
+	_, report = _readinessReport(t, &HealthConfig{ExposeErrors: true})
+	if report.Checks[1].Error == "" {
+		t.Errorf("expected the check error with ExposeErrors")
+	}
+}
+
+// sliceChecker is not comparable, it cannot be a map key.
+type sliceChecker struct {
+	hosts []string
+}
+
+func (s sliceChecker) Name() string {
+	return "hosts"
+}
+
+func (s sliceChecker) Check(ctx context.Context) error {
+	return nil
+}
+
+func TestHealthRunNonComparableChecker(t *testing.T) {
+	health := NewHealth(nil)
+	for i := 0; i < 2; i++ {
+		report := health.Run(context.Background(), sliceChecker{hosts: []string{"a", "b"}})
+		if report.Status != HealthStatusUp {
+			t.Errorf("expected the report to be up, got %s", report.Status)
+		}
+	}
+}
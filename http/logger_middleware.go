@@ -26,8 +26,8 @@ Returns:
 - A `gin.HandlerFunc` that can be used as middleware in a Gin router.
 */
 func LoggerMiddleware() fiber.Handler {
-	slogfiber.TraceIDKey = TraceIDCtxKey
-	slogfiber.RequestIDKey = RequestIDCtxKey
+	slogfiber.TraceIDKey = TraceIDCtxKey.String()
+	slogfiber.RequestIDKey = RequestIDCtxKey.String()
 	slogfiber.RequestIDHeaderKey = RequestIDHeader
 	slogfiber.RequestBodyMaxSize = 64 * 1024  // 64KB
 	slogfiber.ResponseBodyMaxSize = 64 * 1024 // 64KB
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MeterName is the instrumentation scope of the metrics recorded by the http package.
+const MeterName = "github.com/ppabimanyu/compage/http"
+
+// HTTPServerRequestsName is the status class counter recorded by MetricsMiddleware.
+// It is not part of the semantic conventions, it eases alerting on error rates
+// without querying the duration histogram.
+const HTTPServerRequestsName = "http.server.requests"
+
+// StatusClassKey is the attribute holding the status class of the response, e.g. "2xx".
+const StatusClassKey = attribute.Key("http.response.status_class")
+
+type serverMetrics struct {
+	duration      metric.Float64Histogram
+	active        metric.Int64UpDownCounter
+	requestSize   metric.Int64Histogram
+	responseSize  metric.Int64Histogram
+	statusClasses metric.Int64Counter
+}
+
+func _newServerMetrics(meter metric.Meter) (*serverMetrics, error) {
+	var m serverMetrics
+	var err, e error
+	m.duration, e = meter.Float64Histogram(semconv.HTTPServerRequestDurationName,
+		metric.WithUnit(semconv.HTTPServerRequestDurationUnit),
+		metric.WithDescription(semconv.HTTPServerRequestDurationDescription),
+		// Buckets advised by the semantic conventions
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10),
+	)
+	err = errors.Join(err, e)
+	m.active, e = meter.Int64UpDownCounter(semconv.HTTPServerActiveRequestsName,
+		metric.WithUnit(semconv.HTTPServerActiveRequestsUnit),
+		metric.WithDescription(semconv.HTTPServerActiveRequestsDescription),
+	)
+	err = errors.Join(err, e)
+	m.requestSize, e = meter.Int64Histogram(semconv.HTTPServerRequestBodySizeName,
+		metric.WithUnit(semconv.HTTPServerRequestBodySizeUnit),
+		metric.WithDescription(semconv.HTTPServerRequestBodySizeDescription),
+	)
+	err = errors.Join(err, e)
+	m.responseSize, e = meter.Int64Histogram(semconv.HTTPServerResponseBodySizeName,
+		metric.WithUnit(semconv.HTTPServerResponseBodySizeUnit),
+		metric.WithDescription(semconv.HTTPServerResponseBodySizeDescription),
+	)
+	err = errors.Join(err, e)
+	m.statusClasses, e = meter.Int64Counter(HTTPServerRequestsName,
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of HTTP server requests by response status class."),
+	)
+	err = errors.Join(err, e)
+	return &m, err
+}
+
+/*
+MetricsMiddleware records the HTTP server metrics of the OpenTelemetry semantic conventions
+on the global meter provider:
+  - http.server.request.duration: histogram of the request duration in seconds.
+  - http.server.active_requests: number of requests in flight.
+  - http.server.request.body.size and http.server.response.body.size: histograms of the body sizes in bytes.
+  - http.server.requests: counter of requests by status class, see StatusClassKey.
+
+The attributes are the method, scheme, protocol version, status code and the route template,
+e.g. "/users/:id", never the raw path, so the cardinality stays bounded.
+NewServer installs it when Config.Metrics is set.
+*/
+func MetricsMiddleware() fiber.Handler {
+	m, err := _newServerMetrics(otel.Meter(MeterName))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		ctx := c.UserContext()
+		activeAttrs := metric.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(_httpMethod(c)),
+			semconv.URLScheme(c.Protocol()),
+		)
+		m.active.Add(ctx, 1, activeAttrs)
+		defer m.active.Add(ctx, -1, activeAttrs)
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = _errorStatus(err)
+		}
+		attrs := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(_httpMethod(c)),
+			semconv.URLScheme(c.Protocol()),
+			semconv.NetworkProtocolVersion(strings.TrimPrefix(string(c.Request().Header.Protocol()), "HTTP/")),
+			semconv.HTTPResponseStatusCode(status),
+		}
+		if route := _routeTemplate(c, status); route != "" {
+			attrs = append(attrs, semconv.HTTPRoute(route))
+		}
+		if status >= fiber.StatusInternalServerError {
+			attrs = append(attrs, semconv.ErrorTypeKey.String(strconv.Itoa(status)))
+		}
+		set := metric.WithAttributeSet(attribute.NewSet(attrs...))
+
+		m.duration.Record(ctx, time.Since(start).Seconds(), set)
+		m.requestSize.Record(ctx, int64(len(c.Request().Body())), set)
+		m.responseSize.Record(ctx, int64(len(c.Response().Body())), set)
+		m.statusClasses.Add(ctx, 1, set, metric.WithAttributes(StatusClassKey.String(strconv.Itoa(status/100)+"xx")))
+		return err
+	}
+}
+
+// PrometheusHandler serves the metrics of the gatherer in the Prometheus exposition format.
+// NewServer mounts it on Config.PrometheusPath when Config.Prometheus is set.
+func PrometheusHandler(gatherer prometheus.Gatherer) fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+}
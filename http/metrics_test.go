@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+	defer provider.Shutdown(context.Background())
+
+	app := fiber.New()
+	app.Use(MetricsMiddleware())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		return c.SendString("hello")
+	})
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	metrics := map[string]metricdata.Aggregation{}
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	duration, ok := metrics[semconv.HTTPServerRequestDurationName].(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("missing %s", semconv.HTTPServerRequestDurationName)
+	}
+	counts := map[string]uint64{}
+	for _, point := range duration.DataPoints {
+		route, _ := point.Attributes.Value(semconv.HTTPRouteKey)
+		counts[route.AsString()] += point.Count
+	}
+	// The raw paths are grouped under the route template, unmatched requests have no route
+	if counts["/users/:id"] != 2 || counts[""] != 1 {
+		t.Errorf("unexpected counts by route %v", counts)
+	}
+
+	classes, ok := metrics[HTTPServerRequestsName].(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("missing %s", HTTPServerRequestsName)
+	}
+	byClass := map[string]int64{}
+	for _, point := range classes.DataPoints {
+		class, _ := point.Attributes.Value(StatusClassKey)
+		byClass[class.AsString()] += point.Value
+	}
+	if byClass["2xx"] != 2 || byClass["4xx"] != 1 {
+		t.Errorf("unexpected counts by status class %v", byClass)
+	}
+
+	active, ok := metrics[semconv.HTTPServerActiveRequestsName].(metricdata.Sum[int64])
+	if !ok || len(active.DataPoints) != 1 || active.DataPoints[0].Value != 0 {
+		t.Errorf("unexpected active requests %+v", active)
+	}
+}
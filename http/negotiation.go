@@ -0,0 +1,167 @@
+package http
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	MIMEApplicationJSON = "application/json"
+	MIMEApplicationXML  = "application/xml"
+)
+
+// Encoder encodes a response body.
+type Encoder func(v any) ([]byte, error)
+
+type encoderEntry struct {
+	mime   string
+	encode Encoder
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = []encoderEntry{
+		{mime: MIMEApplicationJSON},
+		{mime: MIMEApplicationXML},
+	}
+)
+
+// RegisterEncoder registers an encoder used by Handler.ReturnByAccept for the given media type,
+// replacing any encoder already registered for it. When the client accepts several media types
+// with the same preference, encoders registered first win, so JSON stays the default.
+// JSON and XML are always rendered with the encoders configured on the Fiber app.
+//
+//	http.RegisterEncoder("application/msgpack", msgpack.Marshal)
+//	http.RegisterEncoder("application/yaml", yaml.Marshal)
+func RegisterEncoder(mime string, encoder Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	mime = strings.ToLower(mime)
+	if mime == MIMEApplicationJSON || mime == MIMEApplicationXML {
+		return
+	}
+	for i, entry := range encoders {
+		if entry.mime == mime {
+			encoders[i].encode = encoder
+			return
+		}
+	}
+	encoders = append(encoders, encoderEntry{mime: mime, encode: encoder})
+}
+
+func _encoder(mime string) (Encoder, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	for _, entry := range encoders {
+		if entry.mime == mime && entry.encode != nil {
+			return entry.encode, true
+		}
+	}
+	return nil, false
+}
+
+func _encoderMIMEs() []string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	mimes := make([]string, len(encoders))
+	for i, entry := range encoders {
+		mimes[i] = entry.mime
+	}
+	return mimes
+}
+
+type mediaRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// _specificity ranks how precisely the range matches the offer:
+// 3 for an exact match, 2 for "type/*", 1 for "*/*" and 0 when it does not match.
+func (m mediaRange) _specificity(offer string) int {
+	typ, subtype, _ := strings.Cut(offer, "/")
+	switch {
+	case m.typ == typ && m.subtype == subtype:
+		return 3
+	case m.typ == typ && m.subtype == "*":
+		return 2
+	case m.typ == "*" && m.subtype == "*":
+		return 1
+	default:
+		return 0
+	}
+}
+
+func _parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || typ == "" || subtype == "" {
+			continue
+		}
+
+		r := mediaRange{typ: typ, subtype: subtype, q: 1}
+		charsetOK := true
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "q":
+				q, err := strconv.ParseFloat(value, 64)
+				if err != nil || q < 0 || q > 1 {
+					q = 0
+				}
+				r.q = q
+			case "charset":
+				// Every encoder produces UTF-8
+				charsetOK = strings.EqualFold(value, "utf-8") || strings.EqualFold(value, "utf8") || value == "*"
+			}
+		}
+		if !charsetOK {
+			r.q = 0
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// NegotiateContentType returns the offer preferred by the Accept header, following RFC 9110:
+// the quality of an offer is given by the most specific matching media range, offers with
+// a zero quality are never selected and ties are broken by the order of the offers.
+// An empty Accept header accepts the first offer.
+func NegotiateContentType(accept string, offers ...string) (string, bool) {
+	if len(offers) == 0 {
+		return "", false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+
+	ranges := _parseAccept(accept)
+	type candidate struct {
+		offer string
+		q     float64
+	}
+	var candidates []candidate
+	for _, offer := range offers {
+		best, q := 0, 0.0
+		for _, r := range ranges {
+			if s := r._specificity(strings.ToLower(offer)); s > best {
+				best, q = s, r.q
+			}
+		}
+		if best > 0 && q > 0 {
+			candidates = append(candidates, candidate{offer: offer, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].offer, true
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"testing"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{MIMEApplicationJSON, MIMEApplicationXML, "application/msgpack"}
+	tests := []struct {
+		accept   string
+		expected string
+		ok       bool
+	}{
+		{"", MIMEApplicationJSON, true},
+		{"*/*", MIMEApplicationJSON, true},
+		{"application/json", MIMEApplicationJSON, true},
+		{"application/json; charset=utf-8", MIMEApplicationJSON, true},
+		{"application/xml", MIMEApplicationXML, true},
+		{"text/html, application/xml;q=0.9", MIMEApplicationXML, true},
+		{"application/json;q=0.5, application/xml", MIMEApplicationXML, true},
+		{"application/*;q=0.5, application/msgpack", "application/msgpack", true},
+		{"APPLICATION/XML", MIMEApplicationXML, true},
+		{"*/*, application/json;q=0", MIMEApplicationXML, true},
+		{"application/json; charset=iso-8859-1, application/xml;q=0.1", MIMEApplicationXML, true},
+		{"text/html", "", false},
+		{"application/json;q=0, application/xml;q=0, application/msgpack;q=0", "", false},
+	}
+	for _, test := range tests {
+		actual, ok := NegotiateContentType(test.accept, offers...)
+		if actual != test.expected || ok != test.ok {
+			t.Errorf("%q: expected %q (%v), got %q (%v)", test.accept, test.expected, test.ok, actual, ok)
+		}
+	}
+}
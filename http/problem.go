@@ -0,0 +1,154 @@
+package http
+
+import (
+	"encoding/xml"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/ppabimanyu/compage/reqctx"
+	"slices"
+	"strings"
+)
+
+const (
+	MIMEProblemJSON = "application/problem+json"
+	MIMEProblemXML  = "application/problem+xml"
+)
+
+type ErrorFormat string
+
+const (
+	// EnvelopeErrorFormat renders errors in the Response envelope.
+	// Clients explicitly accepting application/problem+json still receive a Problem.
+	EnvelopeErrorFormat ErrorFormat = "envelope"
+
+	// ProblemErrorFormat renders errors as RFC 7807 Problem documents.
+	ProblemErrorFormat ErrorFormat = "problem"
+)
+
+type errorFormatCtxKeyType struct{}
+
+var errorFormatCtxKey = errorFormatCtxKeyType{}
+
+// ProblemTypeBaseURI is the base of the Problem `type` member, e.g. "https://errors.example.com/".
+// The error code is appended in kebab case. When empty, `type` is "about:blank".
+var ProblemTypeBaseURI = ""
+
+// Problem is an RFC 7807 problem details document.
+// ErrorCode, RequestID and Errors are extension members.
+type Problem struct {
+	XMLName   xml.Name      `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type      string        `json:"type" xml:"type"`
+	Title     string        `json:"title" xml:"title"`
+	Status    int           `json:"status" xml:"status"`
+	Detail    string        `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance  string        `json:"instance,omitempty" xml:"instance,omitempty"`
+	ErrorCode string        `json:"error_code,omitempty" xml:"error_code,omitempty"`
+	RequestID string        `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	Errors    ProblemErrors `json:"errors,omitempty" xml:"errors,omitempty"`
+}
+
+// ProblemErrors maps the invalid fields to their error. In XML every field is an element:
+// <errors><error field="email">must be a valid email</error></errors>
+type ProblemErrors map[string]string
+
+type problemError struct {
+	Field   string `xml:"field,attr"`
+	Message string `xml:",chardata"`
+}
+
+func (e ProblemErrors) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if len(e) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	errs := struct {
+		Errors []problemError `xml:"error"`
+	}{}
+	for _, field := range fields {
+		errs.Errors = append(errs.Errors, problemError{Field: field, Message: e[field]})
+	}
+	return enc.EncodeElement(errs, start)
+}
+
+func (e *ProblemErrors) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var errs struct {
+		Errors []problemError `xml:"error"`
+	}
+	if err := dec.DecodeElement(&errs, &start); err != nil {
+		return err
+	}
+	*e = make(ProblemErrors, len(errs.Errors))
+	for _, item := range errs.Errors {
+		(*e)[item.Field] = item.Message
+	}
+	return nil
+}
+
+// ErrorFormatMiddleware selects the format used by Handler to render errors for every
+// request of the server. NewServer installs it when Config.ErrorFormat is set.
+func ErrorFormatMiddleware(format ErrorFormat) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(errorFormatCtxKey, format)
+		return c.Next()
+	}
+}
+
+// _useProblem reports whether the error response is rendered as a Problem, which is when the
+// Problem format is selected or when mime, the negotiated media type, is a problem media type.
+func (h *Handler) _useProblem(c *fiber.Ctx, r *Response, mime string) bool {
+	if r.Error == nil {
+		return false
+	}
+	format := h.ErrorFormat
+	if format == "" {
+		format, _ = c.Locals(errorFormatCtxKey).(ErrorFormat)
+	}
+	return format == ProblemErrorFormat || mime == MIMEProblemJSON || mime == MIMEProblemXML
+}
+
+// _negotiate returns the offer preferred by the Accept header of the request, or "" when none is acceptable.
+func _negotiate(c *fiber.Ctx, offers ...string) string {
+	mime, _ := NegotiateContentType(c.Get(fiber.HeaderAccept), offers...)
+	return mime
+}
+
+func _problemResponse(c *fiber.Ctx, r *Response) *Problem {
+	p := &Problem{
+		Type:      "about:blank",
+		Title:     utils.StatusMessage(r.StatusCode),
+		Status:    r.StatusCode,
+		Detail:    r.Message,
+		Instance:  c.Path(),
+		RequestID: r.RequestID,
+	}
+	if r.Error != nil {
+		p.ErrorCode = r.Error.Code
+		if errs, ok := r.Error.Details.(map[string]string); ok {
+			p.Errors = errs
+		}
+		if ProblemTypeBaseURI != "" && r.Error.Code != "" {
+			p.Type = ProblemTypeBaseURI + strings.ReplaceAll(strings.ToLower(r.Error.Code), "_", "-")
+		}
+	}
+	return p
+}
+
+// ProblemJSON sends the response as an application/problem+json document.
+func (h *Handler) ProblemJSON(c *fiber.Ctx, r *Response) error {
+	r.RequestID = reqctx.RequestIDFrom(c.Context())
+	return c.Status(r.StatusCode).JSON(_problemResponse(c, r), MIMEProblemJSON)
+}
+
+// ProblemXML sends the response as an application/problem+xml document.
+func (h *Handler) ProblemXML(c *fiber.Ctx, r *Response) error {
+	r.RequestID = reqctx.RequestIDFrom(c.Context())
+	if err := c.Status(r.StatusCode).XML(_problemResponse(c, r)); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, MIMEProblemXML)
+	return nil
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/exception"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func _problemApp(handler *Handler) *fiber.App {
+	app := fiber.New()
+	app.Get("/json", func(c *fiber.Ctx) error {
+		return handler.ExceptionJSON(c, exception.InvalidParameter("Invalid request", map[string]string{"email": "must be a valid email"}))
+	})
+	app.Get("/xml", func(c *fiber.Ctx) error {
+		return handler.ExceptionXML(c, exception.InvalidParameter("Invalid request", map[string]string{"email": "must be a valid email", "age": "must be positive"}))
+	})
+	app.Get("/not-found", func(c *fiber.Ctx) error {
+		return handler.NotFoundJSON(c, "User not found", errors.New("no rows"))
+	})
+	app.Get("/negotiated", func(c *fiber.Ctx) error {
+		return handler.NotFound(c, "User not found", errors.New("no rows"))
+	})
+	return app
+}
+
+func TestProblemJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler *Handler
+		accept  string
+		problem bool
+	}{
+		{"no accept header", &Handler{}, "", false},
+		{"problem accepted", &Handler{}, MIMEProblemJSON, true},
+		{"problem preferred", &Handler{}, "application/json;q=0.5, application/problem+json", true},
+		{"problem refused", &Handler{}, "application/problem+json;q=0, application/json", false},
+		{"json preferred", &Handler{}, "application/json, application/problem+json;q=0.5", false},
+		{"problem format", &Handler{ErrorFormat: ProblemErrorFormat}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/json?page=1", nil)
+			req.Header.Set(fiber.HeaderAccept, tt.accept)
+			resp, err := _problemApp(tt.handler).Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", resp.StatusCode)
+			}
+			if problem := resp.Header.Get(fiber.HeaderContentType) == MIMEProblemJSON; problem != tt.problem {
+				t.Fatalf("expected problem %v, got content type %q", tt.problem, resp.Header.Get(fiber.HeaderContentType))
+			}
+			if !tt.problem {
+				return
+			}
+			var p Problem
+			if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Type != "about:blank" || p.Title != "Bad Request" || p.Status != fiber.StatusBadRequest ||
+				p.Detail != "Invalid request" || p.Instance != "/json" || p.ErrorCode != exception.InvalidParameterCode.ToString() {
+				t.Errorf("unexpected problem %+v", p)
+			}
+			if p.Errors["email"] != "must be a valid email" {
+				t.Errorf("expected the field errors as extension member, got %v", p.Errors)
+			}
+		})
+	}
+}
+
+func TestProblemXML(t *testing.T) {
+	req := httptest.NewRequest(fiber.MethodGet, "/xml", nil)
+	req.Header.Set(fiber.HeaderAccept, MIMEProblemXML)
+	resp, err := _problemApp(&Handler{}).Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header.Get(fiber.HeaderContentType) != MIMEProblemXML {
+		t.Fatalf("unexpected content type %q", resp.Header.Get(fiber.HeaderContentType))
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `<errors><error field="age">must be positive</error><error field="email">must be a valid email</error></errors>`) {
+		t.Errorf("expected the field errors as sorted elements, got %s", body)
+	}
+
+	var p Problem
+	if err := xml.Unmarshal(body, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.XMLName.Space != "urn:ietf:rfc:7807" || p.Status != fiber.StatusBadRequest || len(p.Errors) != 2 || p.Errors["age"] != "must be positive" {
+		t.Errorf("unexpected problem %+v", p)
+	}
+}
+
+func TestExceptionXMLFieldErrors(t *testing.T) {
+	req := httptest.NewRequest(fiber.MethodGet, "/xml", nil)
+	req.Header.Set(fiber.HeaderAccept, fiber.MIMEApplicationXML)
+	resp, err := _problemApp(&Handler{}).Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `<details><error field="age">must be positive</error><error field="email">must be a valid email</error></details>`) {
+		t.Errorf("expected the field errors as sorted elements, got %s", body)
+	}
+}
+
+func TestReturnByAcceptProblem(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", fiber.MIMEApplicationJSON},
+		{MIMEProblemXML, MIMEProblemXML},
+		{"application/xml, application/problem+json;q=0.1", fiber.MIMEApplicationXML},
+		{"application/json;q=0.5, application/problem+json", MIMEProblemJSON},
+		{"application/problem+json;q=0, application/xml;q=0.5", fiber.MIMEApplicationXML},
+		{"text/html", fiber.MIMEApplicationJSON},
+	}
+	app := _problemApp(&Handler{})
+	for _, tt := range tests {
+		req := httptest.NewRequest(fiber.MethodGet, "/negotiated", nil)
+		req.Header.Set(fiber.HeaderAccept, tt.accept)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentType, _, _ := strings.Cut(resp.Header.Get(fiber.HeaderContentType), ";"); contentType != tt.contentType {
+			t.Errorf("%q: expected content type %s, got %s", tt.accept, tt.contentType, contentType)
+		}
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Errorf("%q: expected status 404, got %d", tt.accept, resp.StatusCode)
+		}
+	}
+}
+
+func TestProblemTypeBaseURI(t *testing.T) {
+	ProblemTypeBaseURI = "https://errors.example.com/"
+	defer func() { ProblemTypeBaseURI = "" }()
+
+	resp, err := _problemApp(&Handler{ErrorFormat: ProblemErrorFormat}).Test(httptest.NewRequest(fiber.MethodGet, "/not-found", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p Problem
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Type != "https://errors.example.com/not-found" || p.Status != fiber.StatusNotFound {
+		t.Errorf("unexpected problem %+v", p)
+	}
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/exception"
+	"github.com/ppabimanyu/compage/ratelimit"
+	"github.com/ppabimanyu/compage/reqctx"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+)
+
+// The rate limit headers of the IETF RateLimit header fields draft.
+var (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+)
+
+// RateLimitKey returns the key identifying the client of the request, or "" when the request
+// does not carry it. The keys are prefixed by their kind so they do not collide.
+type RateLimitKey func(c *fiber.Ctx) string
+
+// IPRateLimitKey identifies the client by its IP.
+func IPRateLimitKey(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// SubjectRateLimitKey identifies the client by the `sub` claim of the token verified by AuthMiddleware.
+func SubjectRateLimitKey(c *fiber.Ctx) string {
+	sub, ok := GetClaims(c)["sub"]
+	if !ok || sub == nil {
+		return ""
+	}
+	return fmt.Sprint("sub:", sub)
+}
+
+// TenantRateLimitKey identifies the client by the tenant stored by TenantMiddleware. The tenant must
+// be verified, e.g. with TenantConfig.Exists or a ClaimTenantResolver, otherwise a client can send
+// the tenant of a victim and exhaust its quota.
+func TenantRateLimitKey(c *fiber.Ctx) string {
+	tenantID := reqctx.TenantIDFrom(c.UserContext())
+	if tenantID == "" {
+		return ""
+	}
+	return "tenant:" + tenantID
+}
+
+// APIKeyRateLimitKey identifies the client by the API key of a request header.
+// The key is hashed, so it is not stored in clear text in Redis.
+// The header is not validated here: use it after a middleware rejecting unknown API keys,
+// otherwise a client escapes its limit by sending a new key on every request.
+func APIKeyRateLimitKey(header string) RateLimitKey {
+	return func(c *fiber.Ctx) string {
+		apiKey := c.Get(header)
+		if apiKey == "" {
+			return ""
+		}
+		sum := sha256.Sum256([]byte(apiKey))
+		return "api_key:" + hex.EncodeToString(sum[:16])
+	}
+}
+
+type RateLimitConfig struct {
+	// Limiter counts the requests, see ratelimit.NewLimiter for a limiter shared through Redis.
+	// Default is ratelimit.NewMemoryLimiter(nil), a token bucket of 100 requests per minute.
+	Limiter ratelimit.Limiter
+
+	// Keys are tried in order and the first non-empty key identifies the client.
+	// Default is IPRateLimitKey.
+	Keys []RateLimitKey
+
+	// Policy is the value of the RateLimit-Policy header, e.g. "100;w=60". It is not sent when empty.
+	Policy string
+
+	// SkipPaths is a list of paths which are not rate limited.
+	// A trailing "*" matches any path with the given prefix.
+	// Default is the default health and metrics paths: "/healthz", "/readyz" and "/metrics".
+	// Use Server.RateLimitMiddleware to skip the paths configured on the server instead.
+	SkipPaths []string
+
+	// Skip is an optional function to skip the middleware for a request.
+	Skip func(c *fiber.Ctx) bool
+}
+
+/*
+RateLimitMiddleware is a Fiber middleware which limits the rate of requests per client.
+
+The middleware performs the following steps:
+ 1. Skips the request when its path matches SkipPaths or Skip returns true.
+ 2. Identifies the client with the first non-empty key of Keys, falling back to its IP.
+ 3. Counts the request with the Limiter and sets the RateLimit-Limit, RateLimit-Remaining and
+    RateLimit-Reset headers, in seconds.
+ 4. Rejects the requests over the limit with an exception.ResourceExhausted, answered with
+    a 429 status and a Retry-After header.
+
+When the Limiter fails, the request is let through.
+
+Install it on the routes or groups to limit, after the middlewares validating the identities
+the Keys read, e.g. AuthMiddleware for SubjectRateLimitKey or TenantMiddleware for
+TenantRateLimitKey. Installed before them, the keys are empty and every client falls back
+to its IP:
+
+	api := server.App().Group("/api", AuthMiddleware(authConfig), RateLimitMiddleware(&RateLimitConfig{
+		Limiter: limiter,
+		Keys:    []RateLimitKey{SubjectRateLimitKey},
+	}))
+*/
+func RateLimitMiddleware(config *RateLimitConfig) fiber.Handler {
+	if config == nil {
+		config = &RateLimitConfig{}
+	}
+	if config.Limiter == nil {
+		config.Limiter = ratelimit.NewMemoryLimiter(nil)
+	}
+	if len(config.Keys) == 0 {
+		config.Keys = []RateLimitKey{IPRateLimitKey}
+	}
+	if config.SkipPaths == nil {
+		config.SkipPaths = []string{"/healthz", "/readyz", "/metrics"}
+	}
+
+	handler := Handler{}
+	return func(c *fiber.Ctx) error {
+		if (config.Skip != nil && config.Skip(c)) || _matchPaths(c.Path(), config.SkipPaths) {
+			return c.Next()
+		}
+
+		var key string
+		for _, getKey := range config.Keys {
+			if key = getKey(c); key != "" {
+				break
+			}
+		}
+		if key == "" {
+			key = IPRateLimitKey(c)
+		}
+
+		result, err := config.Limiter.Allow(c.UserContext(), key)
+		if err != nil {
+			slog.WarnContext(c.UserContext(), "RateLimit: Failed to count request, letting it through", "error", err.Error())
+			return c.Next()
+		}
+
+		c.Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+		c.Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		c.Set(RateLimitResetHeader, _seconds(result.Reset))
+		if config.Policy != "" {
+			c.Set(RateLimitPolicyHeader, config.Policy)
+		}
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, _seconds(result.RetryAfter))
+			return handler.Exception(c, exception.ResourceExhausted("Too many requests", fmt.Errorf("rate limit exceeded, retry in %s", result.RetryAfter.Round(time.Second))))
+		}
+		return c.Next()
+	}
+}
+
+// _seconds formats a duration in whole seconds, rounded up.
+func _seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
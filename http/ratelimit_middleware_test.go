@@ -0,0 +1,123 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/exception"
+	"github.com/ppabimanyu/compage/ratelimit"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(RateLimitMiddleware(&RateLimitConfig{
+		Limiter: ratelimit.NewMemoryLimiter(&ratelimit.Config{Limit: 2, Period: time.Minute}),
+		Keys:    []RateLimitKey{APIKeyRateLimitKey("X-Api-Key")},
+	}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	request := func(apiKey string) *Response {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set("X-Api-Key", apiKey)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := &Response{StatusCode: resp.StatusCode}
+		if resp.StatusCode == fiber.StatusTooManyRequests {
+			if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Header.Get(fiber.HeaderRetryAfter) != "30" {
+				t.Errorf("unexpected Retry-After %q", resp.Header.Get(fiber.HeaderRetryAfter))
+			}
+		}
+		if resp.Header.Get(RateLimitLimitHeader) != "2" {
+			t.Errorf("unexpected RateLimit-Limit %q", resp.Header.Get(RateLimitLimitHeader))
+		}
+		return r
+	}
+
+	for i := 0; i < 2; i++ {
+		if r := request("first"); r.StatusCode != fiber.StatusOK {
+			t.Fatalf("request %d: unexpected status %d", i, r.StatusCode)
+		}
+	}
+	r := request("first")
+	if r.StatusCode != fiber.StatusTooManyRequests || r.Error == nil || r.Error.Code != exception.ResourceExhaustedCode.ToString() {
+		t.Errorf("expected a resource exhausted exception, got %+v", r)
+	}
+	if r := request("second"); r.StatusCode != fiber.StatusOK {
+		t.Errorf("clients must be limited separately, got status %d", r.StatusCode)
+	}
+}
+
+func TestRateLimitMiddlewareAfterAuth(t *testing.T) {
+	app := fiber.New()
+	app.Get("/healthz", RateLimitMiddleware(&RateLimitConfig{
+		Limiter: ratelimit.NewMemoryLimiter(&ratelimit.Config{Limit: 1, Period: time.Minute}),
+	}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	api := app.Group("/api", AuthMiddleware(&AuthConfig{SecretKey: testSecret}), RateLimitMiddleware(&RateLimitConfig{
+		Limiter: ratelimit.NewMemoryLimiter(&ratelimit.Config{Limit: 1, Period: time.Minute}),
+		Keys:    []RateLimitKey{SubjectRateLimitKey},
+	}))
+	api.Get("/me", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	request := func(target, token string) int {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	for i := 0; i < 3; i++ {
+		if status := request("/healthz", ""); status != fiber.StatusOK {
+			t.Fatalf("health probes must not be limited, got status %d", status)
+		}
+	}
+	first := _token(t, map[string]any{"sub": "user-1"}, time.Minute)
+	second := _token(t, map[string]any{"sub": "user-2"}, time.Minute)
+	if status := request("/api/me", first); status != fiber.StatusOK {
+		t.Fatalf("unexpected status %d", status)
+	}
+	if status := request("/api/me", first); status != fiber.StatusTooManyRequests {
+		t.Errorf("expected the subject to be limited, got status %d", status)
+	}
+	// Both clients share the test IP, they are limited by their subject
+	if status := request("/api/me", second); status != fiber.StatusOK {
+		t.Errorf("subjects must be limited separately, got status %d", status)
+	}
+}
+
+func TestServerRateLimitMiddlewareSkipPaths(t *testing.T) {
+	server := NewServer(&Config{Prometheus: true, PrometheusPath: "/internal/metrics"})
+	server.EnableHealth(&HealthConfig{LivenessPath: "/live", ReadinessPath: "/ready"})
+
+	config := &RateLimitConfig{}
+	server.RateLimitMiddleware(config)
+	if !slices.Equal(config.SkipPaths, []string{"/live", "/ready", "/internal/metrics"}) {
+		t.Errorf("expected the paths of the server to be skipped, got %v", config.SkipPaths)
+	}
+
+	config = &RateLimitConfig{SkipPaths: []string{"/status"}}
+	server.RateLimitMiddleware(config)
+	if !slices.Equal(config.SkipPaths, []string{"/status"}) {
+		t.Errorf("expected the configured paths to be kept, got %v", config.SkipPaths)
+	}
+}
@@ -1,14 +1,30 @@
 package http
 
+import "encoding/xml"
+
 type Response struct {
 	StatusCode int    `json:"status_code" xml:"status_code"`
 	RequestID  string `json:"request_id" xml:"request_id"`
 	Message    string `json:"message" xml:"message"`
 	Error      *Error `json:"error" xml:"error"`
 	Data       any    `json:"data" xml:"data"`
+	Meta       any    `json:"meta,omitempty" xml:"meta,omitempty"`
 }
 
 type Error struct {
 	Code    string `json:"error_code" xml:"error_code"`
 	Details any    `json:"details" xml:"details"`
 }
+
+// MarshalXML encodes field errors, which encoding/xml cannot marshal as a map, like ProblemErrors:
+// <details><error field="email">must be a valid email</error></details>
+func (e Error) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	details := e.Details
+	if errs, ok := details.(map[string]string); ok {
+		details = ProblemErrors(errs)
+	}
+	return enc.EncodeElement(struct {
+		Code    string `xml:"error_code"`
+		Details any    `xml:"details"`
+	}{Code: e.Code, Details: details}, start)
+}
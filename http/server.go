@@ -1,13 +1,21 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/prometheus/client_golang/prometheus"
 	"log/slog"
+	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 type Config struct {
@@ -17,19 +25,69 @@ type Config struct {
 	AllowMethods     []string
 	AllowHeaders     []string
 	AllowCredentials bool
+
+	// ErrorFormat is the format of error responses, see ErrorFormatMiddleware.
+	// Default is EnvelopeErrorFormat.
+	ErrorFormat ErrorFormat
+
+	// ShutdownTimeout is the maximum time to wait for in-flight requests
+	// to finish and for shutdown hooks to run once a shutdown is triggered.
+	// Default is 30 seconds.
+	ShutdownTimeout time.Duration
+
+	// PreDrainDelay is the time the server keeps serving after the readiness endpoint
+	// starts reporting DOWN, so load balancers and orchestrators probing it stop routing
+	// traffic before the listener closes. It is not part of ShutdownTimeout.
+	// Default is 0, the listener closes immediately.
+	PreDrainDelay time.Duration
+
+	// Metrics records the HTTP server metrics on the global meter provider, see MetricsMiddleware.
+	// Default is false.
+	Metrics bool
+
+	// Prometheus mounts the Prometheus scrape endpoint on PrometheusPath. It serves the metrics
+	// of prometheus.DefaultRegisterer, where telemetry.SetupOtelSDK registers its Prometheus reader.
+	// Default is false.
+	Prometheus bool
+
+	// PrometheusPath is the path of the Prometheus scrape endpoint.
+	// Default is "/metrics".
+	PrometheusPath string
+}
+
+// ShutdownHook is a function that releases a resource when the server stops.
+// The signature matches the Shutdown method of the providers returned by telemetry.SetupOtelSDK.
+type ShutdownHook func(ctx context.Context) error
+
+type namedShutdownHook struct {
+	name string
+	fn   ShutdownHook
 }
 
 type Server struct {
 	config *Config
 	server *fiber.App
+
+	hooksMu sync.Mutex
+	hooks   []namedShutdownHook
+	health  *Health
 }
 
 func NewServer(config *Config) *Server {
+	if config.ShutdownTimeout == 0 {
+		config.ShutdownTimeout = 30 * time.Second
+	}
+	if config.PrometheusPath == "" {
+		config.PrometheusPath = "/metrics"
+	}
 	server := fiber.New(fiber.Config{
 		EnablePrintRoutes: true,
 		ErrorHandler:      ErrorHandler(),
 	})
 	server.Use(recover.New())
+	if config.ErrorFormat != "" {
+		server.Use(ErrorFormatMiddleware(config.ErrorFormat))
+	}
 	server.Use(cors.New(cors.Config{
 		AllowOrigins:     strings.Join(config.AllowOrigins, ","),
 		AllowMethods:     strings.Join(config.AllowMethods, ","),
@@ -37,10 +95,17 @@ func NewServer(config *Config) *Server {
 		AllowCredentials: config.AllowCredentials,
 	}))
 	server.Use(requestid.New(requestid.Config{
-		Header:     "X-Request-Id",
-		ContextKey: "request_id",
+		Header:     RequestIDHeader,
+		ContextKey: RequestIDCtxKey,
 	}))
 	server.Use(ContextMiddleware())
+	if config.Metrics {
+		server.Use(MetricsMiddleware())
+	}
+	if config.Prometheus {
+		// Mounted before the logger so scrapes do not flood the logs
+		server.Get(config.PrometheusPath, PrometheusHandler(prometheus.DefaultGatherer))
+	}
 	server.Use(LoggerMiddleware())
 	return &Server{
 		server: server,
@@ -58,6 +123,118 @@ func (h *Server) Shutdown() error {
 	return h.server.Shutdown()
 }
 
+// EnableHealth mounts the liveness and readiness endpoints on the server and returns
+// the Health instance used to register checkers. While the server is shutting down
+// the readiness endpoint reports DOWN.
+func (h *Server) EnableHealth(config *HealthConfig) *Health {
+	h.health = NewHealth(config)
+	h.health.Mount(h.server)
+	return h.health
+}
+
+// RateLimitMiddleware returns a RateLimitMiddleware which, when config.SkipPaths is nil, skips
+// the health and Prometheus endpoints mounted on the server, with their configured paths.
+// EnableHealth must be called before it for the health endpoints to be skipped.
+func (h *Server) RateLimitMiddleware(config *RateLimitConfig) fiber.Handler {
+	if config == nil {
+		config = &RateLimitConfig{}
+	}
+	if config.SkipPaths == nil {
+		config.SkipPaths = []string{}
+		if h.health != nil {
+			config.SkipPaths = append(config.SkipPaths, h.health.config.LivenessPath, h.health.config.ReadinessPath)
+		}
+		if h.config.Prometheus {
+			config.SkipPaths = append(config.SkipPaths, h.config.PrometheusPath)
+		}
+	}
+	return RateLimitMiddleware(config)
+}
+
+// OnShutdown registers a hook to be executed after the server has stopped
+// accepting connections and drained in-flight requests.
+// Hooks are executed in reverse registration order, so resources opened first
+// (e.g. the database) are closed last.
+//
+// Parameters:
+// - name: A descriptive name used in logs.
+// - hook: The function to execute.
+func (h *Server) OnShutdown(name string, hook ShutdownHook) {
+	h.hooksMu.Lock()
+	defer h.hooksMu.Unlock()
+	h.hooks = append(h.hooks, namedShutdownHook{name: name, fn: hook})
+}
+
+/*
+Run starts the server and blocks until it is stopped, then shuts it down gracefully.
+
+The server is stopped when one of the following happens:
+- The given context is cancelled.
+- The process receives SIGINT or SIGTERM.
+- The listener fails.
+
+On stop, the readiness endpoint reports DOWN and the server keeps serving for
+Config.PreDrainDelay. It then stops accepting new connections, waits for in-flight requests
+up to Config.ShutdownTimeout, and runs the registered shutdown hooks in reverse
+registration order within the remaining time. All errors are joined and returned.
+*/
+func (h *Server) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- h.Start()
+	}()
+
+	var err error
+	select {
+	case err = <-listenErr:
+		if err != nil {
+			slog.Error("Server: Listener stopped unexpectedly", "error", err.Error())
+		}
+	case <-ctx.Done():
+		slog.Info("Server: Shutdown signal received")
+	}
+	stop()
+	if h.health != nil {
+		h.health.SetReady(false)
+	}
+	if h.config.PreDrainDelay > 0 {
+		slog.Info("Server: Waiting before draining", "delay", h.config.PreDrainDelay.String())
+		time.Sleep(h.config.PreDrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), h.config.ShutdownTimeout)
+	defer cancel()
+
+	slog.Info("Server: Draining in-flight requests", "timeout", h.config.ShutdownTimeout.String())
+	if shutdownErr := h.server.ShutdownWithContext(shutdownCtx); shutdownErr != nil {
+		slog.Error("Server: Failed to drain in-flight requests", "error", shutdownErr.Error())
+		err = errors.Join(err, shutdownErr)
+	}
+
+	return errors.Join(err, h._runShutdownHooks(shutdownCtx))
+}
+
+func (h *Server) _runShutdownHooks(ctx context.Context) error {
+	h.hooksMu.Lock()
+	hooks := h.hooks
+	h.hooks = nil
+	h.hooksMu.Unlock()
+
+	var err error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		slog.Info("Server: Running shutdown hook", "hook", hook.name)
+		if hookErr := hook.fn(ctx); hookErr != nil {
+			slog.Error("Server: Shutdown hook failed", "hook", hook.name, "error", hookErr.Error())
+			err = errors.Join(err, fmt.Errorf("%s: %w", hook.name, hookErr))
+		}
+	}
+	return err
+}
+
 func (h *Server) App() *fiber.App {
 	return h.server
 }
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestServerRunShutdownHooks(t *testing.T) {
+	server := NewServer(&Config{ShutdownTimeout: 100 * time.Millisecond})
+	listening := make(chan struct{})
+	server.App().Hooks().OnListen(func(fiber.ListenData) error {
+		close(listening)
+		return nil
+	})
+
+	var order []string
+	hookErr := errors.New("close failed")
+	server.OnShutdown("database", func(ctx context.Context) error {
+		order = append(order, "database")
+		if _, ok := ctx.Deadline(); !ok {
+			t.Errorf("expected the hooks to run with the shutdown timeout")
+		}
+		return nil
+	})
+	server.OnShutdown("broker", func(ctx context.Context) error {
+		order = append(order, "broker")
+		return hookErr
+	})
+	server.OnShutdown("slow", func(ctx context.Context) error {
+		order = append(order, "slow")
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run(ctx)
+	}()
+	select {
+	case <-listening:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not start")
+	}
+	cancel()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+	if !slices.Equal(order, []string{"slow", "broker", "database"}) {
+		t.Errorf("expected the hooks to run in reverse order, got %v", order)
+	}
+	if !errors.Is(err, hookErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the hook errors to be joined, got %v", err)
+	}
+}
+
+func TestServerRunPreDrainDelay(t *testing.T) {
+	server := NewServer(&Config{PreDrainDelay: 200 * time.Millisecond})
+	server.EnableHealth(nil)
+	listening := make(chan struct{})
+	server.App().Hooks().OnListen(func(fiber.ListenData) error {
+		close(listening)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run(ctx)
+	}()
+	select {
+	case <-listening:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not start")
+	}
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	// The readiness endpoint reports DOWN while the server still serves
+	resp, err := server.App().Test(httptest.NewRequest(fiber.MethodGet, "/readyz", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("expected the readiness endpoint to report DOWN, got %d", resp.StatusCode)
+	}
+	select {
+	case <-done:
+		t.Fatal("the server stopped before the pre-drain delay")
+	default:
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the pre-drain delay")
+	}
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+)
+
+// TenantResolver returns the tenant ID of the request, or "" when the request does not carry one.
+type TenantResolver func(c *fiber.Ctx) string
+
+// HeaderTenantResolver resolves the tenant from a request header, e.g. TenantIDHeader.
+// The header is chosen by the client, so it must be paired with a membership check: either
+// TenantConfig.Exists or a middleware after TenantMiddleware must verify that the authenticated
+// user belongs to the tenant. Prefer ClaimTenantResolver when the token carries the tenant.
+func HeaderTenantResolver(header string) TenantResolver {
+	return func(c *fiber.Ctx) string {
+		return strings.TrimSpace(c.Get(header))
+	}
+}
+
+// SubdomainTenantResolver resolves the tenant from the subdomain right below the base domain,
+// e.g. "acme" for "acme.example.com" and "api.acme.example.com" with the base domain "example.com".
+func SubdomainTenantResolver(baseDomain string) TenantResolver {
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+	return func(c *fiber.Ctx) string {
+		subdomains, ok := strings.CutSuffix(strings.ToLower(c.Hostname()), suffix)
+		if !ok {
+			return ""
+		}
+		return subdomains[strings.LastIndex(subdomains, ".")+1:]
+	}
+}
+
+// ClaimTenantResolver resolves the tenant from a claim of the token verified by AuthMiddleware,
+// which must run before the TenantMiddleware.
+func ClaimTenantResolver(claim string) TenantResolver {
+	return func(c *fiber.Ctx) string {
+		value, ok := GetClaims(c)[claim]
+		if !ok || value == nil {
+			return ""
+		}
+		// Numeric IDs are decoded from JSON as float64
+		return fmt.Sprint(value)
+	}
+}
+
+// PathTenantResolver resolves the tenant from the path segment following the prefix,
+// e.g. "acme" for "/tenants/acme/users" with the prefix "/tenants".
+// With an empty prefix, the first path segment is used.
+func PathTenantResolver(prefix string) TenantResolver {
+	prefix = "/" + strings.Trim(prefix, "/")
+	return func(c *fiber.Ctx) string {
+		path := c.Path()
+		if prefix != "/" {
+			var ok bool
+			if path, ok = strings.CutPrefix(path, prefix); !ok || !strings.HasPrefix(path, "/") {
+				return ""
+			}
+		}
+		segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+		return segment
+	}
+}
+
+// StaticTenants returns a TenantConfig.Exists function accepting only the given tenants.
+func StaticTenants(tenantIDs ...string) func(ctx context.Context, tenantID string) (bool, error) {
+	tenants := make(map[string]struct{}, len(tenantIDs))
+	for _, id := range tenantIDs {
+		tenants[id] = struct{}{}
+	}
+	return func(_ context.Context, tenantID string) (bool, error) {
+		_, ok := tenants[tenantID]
+		return ok, nil
+	}
+}
+
+type TenantConfig struct {
+	// Resolvers are tried in order and the first non-empty tenant ID wins.
+	// Default is HeaderTenantResolver(TenantIDHeader).
+	Resolvers []TenantResolver
+
+	// Exists reports whether the tenant is known, e.g. by looking it up in the database.
+	// Requests with an unknown tenant are answered with Handler.NotFound.
+	// Required, TenantMiddleware panics without it.
+	Exists func(ctx context.Context, tenantID string) (bool, error)
+
+	// Optional lets the requests without a tenant through, without a tenant in the context.
+	// Default is false, they are answered with Handler.BadRequest.
+	Optional bool
+
+	// SkipPaths is a list of paths which do not require a tenant.
+	// A trailing "*" matches any path with the given prefix.
+	SkipPaths []string
+
+	// Skip is an optional function to skip the middleware for a request.
+	Skip func(c *fiber.Ctx) bool
+}
+
+/*
+TenantMiddleware is a Fiber middleware which resolves the tenant of the request.
+
+The middleware performs the following steps:
+ 1. Skips the request when its path matches SkipPaths or Skip returns true, clearing the tenant ID.
+ 2. Resolves the tenant ID with the Resolvers, and rejects the request when none is found
+    unless Optional is set.
+ 3. Rejects the request when Exists reports an unknown tenant.
+ 4. Stores the tenant ID under TenantIDCtxKey in the Fiber Locals and in c.UserContext(),
+    so reqctx.TenantIDFrom(ctx) returns it and the gormutils.TenantPlugin scopes the queries run
+    with c.UserContext().
+
+It must be installed after ContextMiddleware, and after AuthMiddleware with a ClaimTenantResolver.
+The default HeaderTenantResolver trusts the client, so the membership of the user in the tenant
+must be checked as well, see HeaderTenantResolver.
+*/
+func TenantMiddleware(config *TenantConfig) fiber.Handler {
+	if config == nil {
+		panic("config cannot be nil")
+	}
+	if config.Exists == nil {
+		panic("exists must be provided")
+	}
+	if len(config.Resolvers) == 0 {
+		config.Resolvers = []TenantResolver{HeaderTenantResolver(TenantIDHeader)}
+	}
+
+	handler := Handler{}
+	return func(c *fiber.Ctx) error {
+		if (config.Skip != nil && config.Skip(c)) || _matchPaths(c.Path(), config.SkipPaths) {
+			// Skipped requests are not scoped, whatever tenant an earlier middleware stored
+			_setCtxValue(c, TenantIDCtxKey, "")
+			return c.Next()
+		}
+
+		var tenantID string
+		for _, resolve := range config.Resolvers {
+			if tenantID = resolve(c); tenantID != "" {
+				break
+			}
+		}
+		if tenantID == "" {
+			if config.Optional {
+				// Requests without a tenant are not scoped, whatever tenant an earlier middleware stored
+				_setCtxValue(c, TenantIDCtxKey, "")
+				return c.Next()
+			}
+			return handler.BadRequest(c, "Missing tenant", errors.New("tenant not found"))
+		}
+
+		exists, err := config.Exists(c.UserContext(), tenantID)
+		if err != nil {
+			return handler.InternalServerError(c, "Failed to resolve tenant", err)
+		}
+		if !exists {
+			return handler.NotFound(c, "Unknown tenant", fmt.Errorf("unknown tenant: %s", tenantID))
+		}
+
+		_setCtxValue(c, TenantIDCtxKey, tenantID)
+		return c.Next()
+	}
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/reqctx"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTenantResolvers(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver TenantResolver
+		target   string
+		host     string
+		want     string
+	}{
+		{"header", HeaderTenantResolver(TenantIDHeader), "/users", "", "acme"},
+		{"subdomain", SubdomainTenantResolver("example.com"), "/users", "api.acme.example.com", "acme"},
+		{"other domain", SubdomainTenantResolver("example.com"), "/users", "acme.example.org", ""},
+		{"path prefix", PathTenantResolver("/tenants"), "/tenants/acme/users", "", "acme"},
+		{"path without prefix", PathTenantResolver(""), "/acme/users", "", "acme"},
+		{"path other prefix", PathTenantResolver("/tenants"), "/tenantsx/acme", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				got = tt.resolver(c)
+				return c.SendStatus(fiber.StatusOK)
+			})
+			req := httptest.NewRequest(fiber.MethodGet, tt.target, nil)
+			req.Header.Set(TenantIDHeader, "acme")
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if _, err := app.Test(req); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	var tenantID string
+	app := fiber.New()
+	app.Use(ContextMiddleware(), TenantMiddleware(&TenantConfig{
+		Exists:    StaticTenants("acme"),
+		SkipPaths: []string{"/health"},
+	}))
+	app.Get("/*", func(c *fiber.Ctx) error {
+		tenantID = reqctx.TenantIDFrom(c.UserContext())
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		path   string
+		tenant string
+		status int
+		want   string
+	}{
+		{"/users", "acme", fiber.StatusOK, "acme"},
+		{"/users", "globex", fiber.StatusNotFound, ""},
+		{"/users", "", fiber.StatusBadRequest, ""},
+		{"/health", "", fiber.StatusOK, ""},
+		// The header of a skipped request must not scope its queries
+		{"/health", "acme", fiber.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		tenantID = "unset"
+		req := httptest.NewRequest(fiber.MethodGet, tt.path, nil)
+		if tt.tenant != "" {
+			req.Header.Set(TenantIDHeader, tt.tenant)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s with tenant %q: expected status %d, got %d", tt.path, tt.tenant, tt.status, resp.StatusCode)
+		}
+		if tt.status == fiber.StatusOK && tenantID != tt.want {
+			t.Errorf("%s: unexpected tenant in context %q", tt.path, tenantID)
+		}
+	}
+}
+
+func TestTenantMiddlewareRequiresExists(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected TenantMiddleware to panic without Exists")
+		}
+	}()
+	TenantMiddleware(&TenantConfig{})
+}
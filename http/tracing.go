@@ -0,0 +1,124 @@
+package http
+
+import (
+	"crypto/rand"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// TracerName is the instrumentation scope of the spans started by the http package.
+const TracerName = "github.com/ppabimanyu/compage/http"
+
+// headerCarrier adapts the Fiber request and response headers to propagation.TextMapCarrier.
+// Get and Keys read the request headers, Set writes the response headers.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.c.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h.c.GetReqHeaders()))
+	for key := range h.c.GetReqHeaders() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// _legacySpanContext builds a remote parent from the legacy `X-Trace-Id` header, so clients
+// that do not send `traceparent` still join the trace they expect. The header must hold
+// 32 hexadecimal characters, dashes being ignored so UUIDs are accepted.
+// The parent is not marked as sampled, the sampler of the tracer provider decides: the default
+// parent based sampler drops these spans unless configured with WithRemoteParentNotSampled.
+func _legacySpanContext(traceID string) (trace.SpanContext, bool) {
+	tid, err := trace.TraceIDFromHex(strings.ReplaceAll(strings.ToLower(traceID), "-", ""))
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	// The legacy header carries no parent span, a random one keeps the span context valid
+	var sid trace.SpanID
+	if _, err := rand.Read(sid[:]); err != nil {
+		return trace.SpanContext{}, false
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: tid,
+		SpanID:  sid,
+		Remote:  true,
+	}), true
+}
+
+var knownMethods = map[string]bool{
+	fiber.MethodGet: true, fiber.MethodHead: true, fiber.MethodPost: true, fiber.MethodPut: true,
+	fiber.MethodPatch: true, fiber.MethodDelete: true, fiber.MethodConnect: true,
+	fiber.MethodOptions: true, fiber.MethodTrace: true,
+}
+
+// _httpMethod returns the method following the semantic conventions,
+// unknown methods are reported as "_OTHER" to keep the cardinality bounded.
+func _httpMethod(c *fiber.Ctx) string {
+	if knownMethods[c.Method()] {
+		return c.Method()
+	}
+	return "_OTHER"
+}
+
+// _serverSpanAttributes returns the request attributes of the server span. The query string
+// is left out as it may carry tokens or personal data.
+func _serverSpanAttributes(c *fiber.Ctx) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(_httpMethod(c)),
+		semconv.URLScheme(c.Protocol()),
+		semconv.URLPath(c.Path()),
+		semconv.ServerAddress(c.Hostname()),
+		semconv.ClientAddress(c.IP()),
+		semconv.NetworkProtocolVersion(strings.TrimPrefix(string(c.Request().Header.Protocol()), "HTTP/")),
+	}
+	if userAgent := c.Get(fiber.HeaderUserAgent); userAgent != "" {
+		attrs = append(attrs, semconv.UserAgentOriginal(userAgent))
+	}
+	if _httpMethod(c) == "_OTHER" {
+		attrs = append(attrs, semconv.HTTPRequestMethodOriginal(c.Method()))
+	}
+	return attrs
+}
+
+// _routeTemplate returns the path template of the matched route, e.g. "/users/:id",
+// or "" when no route matched the request.
+func _routeTemplate(c *fiber.Ctx, status int) string {
+	if status == fiber.StatusNotFound || c.Route() == nil {
+		return ""
+	}
+	return c.Route().Path
+}
+
+// _endServerSpan records the outcome of the request on the span.
+// Errors returned by the handlers are not rendered yet, their status is the one
+// the Fiber error handler will use.
+func _endServerSpan(c *fiber.Ctx, span trace.Span, err error) {
+	status := c.Response().StatusCode()
+	if err != nil {
+		status = _errorStatus(err)
+		span.RecordError(err)
+	}
+
+	if route := _routeTemplate(c, status); route != "" {
+		span.SetName(_httpMethod(c) + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	// Client errors are not errors of the server span
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, "")
+	}
+	span.End()
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ppabimanyu/compage/reqctx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http/httptest"
+	"testing"
+)
+
+func _tracingApp(t *testing.T, options ...sdktrace.TracerProviderOption) (*fiber.App, *tracetest.SpanRecorder, *string) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(append(options, sdktrace.WithSpanProcessor(recorder))...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { _ = provider.Shutdown(t.Context()) })
+
+	var traceID string
+	app := fiber.New()
+	app.Use(ContextMiddleware())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		traceID = reqctx.TraceIDFrom(c.UserContext())
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app, recorder, &traceID
+}
+
+func TestContextMiddlewareTraceparent(t *testing.T) {
+	app, recorder, traceID := _tracingApp(t)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/users/42?token=secret", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /users/:id" || span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("unexpected span %q of kind %v", span.Name(), span.SpanKind())
+	}
+	if span.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("unexpected parent %v", span.Parent().SpanID())
+	}
+	if *traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("unexpected trace ID in context %q", *traceID)
+	}
+	attrs := map[string]string{}
+	for _, attr := range span.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs[string(semconv.HTTPRouteKey)] != "/users/:id" || attrs[string(semconv.HTTPResponseStatusCodeKey)] != "200" {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+	if _, ok := attrs[string(semconv.URLQueryKey)]; ok {
+		t.Errorf("the query string must not be recorded, got %v", attrs)
+	}
+}
+
+func TestContextMiddlewareLegacyTraceID(t *testing.T) {
+	tests := []struct {
+		name    string
+		sampler sdktrace.Sampler
+		sampled bool
+	}{
+		{"default sampler", sdktrace.ParentBased(sdktrace.AlwaysSample()), false},
+		{"remote parent not sampled", sdktrace.ParentBased(sdktrace.AlwaysSample(), sdktrace.WithRemoteParentNotSampled(sdktrace.AlwaysSample())), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, recorder, traceID := _tracingApp(t, sdktrace.WithSampler(tt.sampler))
+
+			req := httptest.NewRequest(fiber.MethodGet, "/users/42", nil)
+			req.Header.Set(TraceIDHeader, "6f1c1a4e-2b7d-4c55-9a8e-3c2d1b0a9f8e")
+			if _, err := app.Test(req); err != nil {
+				t.Fatal(err)
+			}
+
+			if *traceID != "6f1c1a4e2b7d4c559a8e3c2d1b0a9f8e" {
+				t.Errorf("unexpected trace ID in context %q", *traceID)
+			}
+			spans := recorder.Ended()
+			if sampled := len(spans) == 1; sampled != tt.sampled {
+				t.Fatalf("expected sampled %v, got %d spans", tt.sampled, len(spans))
+			}
+			if tt.sampled && spans[0].SpanContext().TraceID().String() != *traceID {
+				t.Errorf("the span does not continue the legacy trace")
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package jwt
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"time"
+)
+
+// RegisteredClaims holds the registered claims (RFC 7519 section 4.1).
+// Embed it in a struct to define typed claims:
+//
+//	type UserClaims struct {
+//		jwt.RegisteredClaims
+//		Roles    []string `json:"roles"`
+//		TenantID string   `json:"tenant_id"`
+//	}
+type RegisteredClaims struct {
+	jwt.RegisteredClaims
+}
+
+// Registered returns the registered claims so they can be filled when signing.
+func (c *RegisteredClaims) Registered() *jwt.RegisteredClaims {
+	return &c.RegisteredClaims
+}
+
+// Claims is implemented by any struct embedding RegisteredClaims.
+type Claims interface {
+	jwt.Claims
+	Registered() *jwt.RegisteredClaims
+}
+
+type VerifyOptions struct {
+	// Issuer is the expected `iss` claim. It is not checked when empty.
+	Issuer string
+
+	// Audience is the expected `aud` claim. It is not checked when empty.
+	Audience string
+
+	// Subject is the expected `sub` claim. It is not checked when empty.
+	Subject string
+
+	// Leeway is the clock skew tolerated when validating `exp`, `nbf` and `iat`.
+	Leeway time.Duration
+
+	// RequireExpiration rejects tokens without an `exp` claim.
+	RequireExpiration bool
+
+	// ValidateIssuedAt rejects tokens with an `iat` claim in the future.
+	ValidateIssuedAt bool
+}
+
+func (o *VerifyOptions) _parserOptions() []jwt.ParserOption {
+	if o == nil {
+		return nil
+	}
+	var opts []jwt.ParserOption
+	if o.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(o.Issuer))
+	}
+	if o.Audience != "" {
+		opts = append(opts, jwt.WithAudience(o.Audience))
+	}
+	if o.Subject != "" {
+		opts = append(opts, jwt.WithSubject(o.Subject))
+	}
+	if o.Leeway > 0 {
+		opts = append(opts, jwt.WithLeeway(o.Leeway))
+	}
+	if o.RequireExpiration {
+		opts = append(opts, jwt.WithExpirationRequired())
+	}
+	if o.ValidateIssuedAt {
+		opts = append(opts, jwt.WithIssuedAt())
+	}
+	return opts
+}
+
+// GenTokenWithClaims is the typed variant of GenToken.
+func GenTokenWithClaims[T Claims](claims T, secretKey []byte, exp time.Duration) (string, error) {
+	return SignClaims(hmacSigner(secretKey), claims, exp)
+}
+
+// VerifyTokenWithClaims is the typed variant of VerifyToken.
+//
+//	claims, err := jwt.VerifyTokenWithClaims[UserClaims](token, secret, &jwt.VerifyOptions{Issuer: "auth"})
+func VerifyTokenWithClaims[T any, PT interface {
+	*T
+	jwt.Claims
+}](tokenString string, secretKey []byte, opts *VerifyOptions) (*T, error) {
+	return VerifyClaims[T, PT](hmacSigner(secretKey), tokenString, opts)
+}
+
+// SignClaims signs typed claims with the given signer.
+// Like Sign, the `jti` claim is generated when missing, and `exp` and `iat` are always set.
+func SignClaims[T Claims](signer Signer, claims T, exp time.Duration) (string, error) {
+	registered := claims.Registered()
+	if registered.ID == "" {
+		registered.ID = uuid.NewString()
+	}
+	now := time.Now()
+	registered.ExpiresAt = jwt.NewNumericDate(now.Add(exp))
+	registered.IssuedAt = jwt.NewNumericDate(now)
+	return signer.Sign(claims)
+}
+
+// VerifyClaims parses and validates a token into typed claims with the key resolved by the verifier.
+func VerifyClaims[T any, PT interface {
+	*T
+	jwt.Claims
+}](verifier Verifier, tokenString string, opts *VerifyOptions) (*T, error) {
+	claims := PT(new(T))
+	token, err := jwt.ParseWithClaims(tokenString, claims, verifier.Keyfunc, opts._parserOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return (*T)(claims), nil
+}
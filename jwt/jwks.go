@@ -0,0 +1,186 @@
+package jwt
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"math/big"
+)
+
+// JWK is the JSON Web Key representation (RFC 7517) of a public key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA public key members
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC and OKP public key members
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public JWK of the key.
+// Symmetric keys cannot be published and return an error.
+func (k *Key) JWK() (JWK, error) {
+	jwk := JWK{
+		Kid: k.ID,
+		Use: "sig",
+		Alg: k.Method.Alg(),
+	}
+	switch key := k.VerifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = _b64(key.N.Bytes())
+		jwk.E = _b64(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = key.Curve.Params().Name
+		jwk.X = _b64(key.X.FillBytes(make([]byte, size)))
+		jwk.Y = _b64(key.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = _b64(key)
+	default:
+		return JWK{}, fmt.Errorf("jwt: key %s of type %T cannot be published", k.ID, k.VerifyKey)
+	}
+	return jwk, nil
+}
+
+// PublicKey converts the JWK back into a verification only Key.
+func (j JWK) PublicKey() (*Key, error) {
+	var key any
+	switch j.Kty {
+	case "RSA":
+		n, err := _unb64(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := _unb64(j.E)
+		if err != nil {
+			return nil, err
+		}
+		if len(n) == 0 || len(e) == 0 {
+			return nil, errors.New("jwt: invalid RSA public key")
+		}
+		key = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	case "EC":
+		pub, err := _ecPublicKey(j.Crv, j.X, j.Y)
+		if err != nil {
+			return nil, err
+		}
+		key = pub
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwt: unsupported OKP curve: %s", j.Crv)
+		}
+		x, err := _unb64(j.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("jwt: invalid Ed25519 public key size")
+		}
+		key = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("jwt: unsupported key type: %s", j.Kty)
+	}
+
+	k, err := NewPublicKey(j.Kid, key)
+	if err != nil {
+		return nil, err
+	}
+	if j.Alg != "" && j.Alg != k.Method.Alg() {
+		return nil, fmt.Errorf("%w: key %s declares %s", ErrUnexpectedMethod, j.Kid, j.Alg)
+	}
+	return k, nil
+}
+
+// JWKS returns the public keys of the set. Symmetric keys are skipped.
+func (ks *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range ks.Keys() {
+		jwk, err := key.JWK()
+		if err != nil {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+// JWKSHandler is a Fiber handler serving the public keys of the set,
+// typically mounted on "/.well-known/jwks.json".
+func JWKSHandler(ks *KeySet) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(ks.JWKS())
+	}
+}
+
+func _ecPublicKey(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	var ecdhCurve ecdh.Curve
+	switch crv {
+	case "P-256":
+		curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, fmt.Errorf("jwt: unsupported EC curve: %s", crv)
+	}
+
+	xBytes, err := _unb64(x)
+	if err != nil {
+		return nil, err
+	}
+	yBytes, err := _unb64(y)
+	if err != nil {
+		return nil, err
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	if len(xBytes) != size || len(yBytes) != size {
+		return nil, errors.New("jwt: invalid EC coordinate size")
+	}
+
+	// Validate that the point is on the curve
+	point := append([]byte{4}, append(xBytes, yBytes...)...)
+	if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(xBytes),
+		Y:     new(big.Int).SetBytes(yBytes),
+	}, nil
+}
+
+func _b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func _unb64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,187 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	ks, _ := NewKeySet(NewRSAKey("rs", rsaKey), NewHMACKey("secret", []byte("secret")))
+
+	app := fiber.New()
+	app.Get("/.well-known/jwks.json", JWKSHandler(ks))
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var jwks JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// The HMAC secret must never be published
+	if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "rs" || jwks.Keys[0].Kty != "RSA" {
+		t.Errorf("expected only the rsa key to be published, got %+v", jwks.Keys)
+	}
+}
+
+func TestRemoteJWKS(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ecdsa key: %v", err)
+	}
+	first, _ := NewECDSAKey("v1", ecKey)
+	issuer, _ := NewKeySet(first)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(issuer.JWKS())
+	}))
+	defer server.Close()
+
+	remote, err := NewRemoteJWKS(context.Background(), &RemoteJWKSConfig{
+		URL:                server.URL,
+		MinRefreshInterval: time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer remote.Close()
+
+	token, _ := issuer.GenToken(map[string]any{"sub": "user-1"}, time.Minute)
+	claims, err := remote.VerifyToken(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claims["sub"] != "user-1" {
+		t.Errorf("expected sub to be user-1, got %v", claims["sub"])
+	}
+
+	// A key rotated on the issuer is fetched on the first token carrying its kid
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	_ = issuer.Rotate(NewRSAKey("v2", rsaKey))
+	token, _ = issuer.GenToken(map[string]any{"sub": "user-2"}, time.Minute)
+	if _, err := remote.VerifyToken(token); err != nil {
+		t.Errorf("expected rotated key to verify, got %v", err)
+	}
+
+	// Tokens signed with keys the issuer does not publish are rejected
+	unknown, _ := NewKeySet(NewRSAKey("v3", rsaKey))
+	token, _ = unknown.GenToken(map[string]any{"sub": "user-3"}, time.Minute)
+	if _, err := remote.VerifyToken(token); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestRemoteJWKSRefreshCooldown(t *testing.T) {
+	issuer, _ := NewKeySet(NewRSAKey("v1", _rsaKey(t)))
+
+	var fetches atomic.Int32
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if down.Load() {
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(issuer.JWKS())
+	}))
+	defer server.Close()
+
+	remote, err := NewRemoteJWKS(context.Background(), &RemoteJWKSConfig{
+		URL:                server.URL,
+		MinRefreshInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer remote.Close()
+
+	// Right after the initial fetch, a forged kid does not trigger a refresh
+	forged, _ := NewKeySet(NewRSAKey("forged", _rsaKey(t)))
+	token, _ := forged.GenToken(map[string]any{"sub": "attacker"}, time.Minute)
+	if _, err := remote.VerifyToken(token); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("expected only the initial fetch, got %d", n)
+	}
+
+	// Once the cooldown is over, concurrent misses on a failing endpoint share a single attempt
+	down.Store(true)
+	remote.refreshMu.Lock()
+	remote.lastAttempt = time.Time{}
+	remote.refreshMu.Unlock()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = remote.VerifyToken(token)
+		}()
+	}
+	wg.Wait()
+	if _, err := remote.VerifyToken(token); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("expected a single refresh attempt for the failed endpoint, got %d fetches", n-1)
+	}
+}
+
+func TestRemoteJWKSKeepsKeysOnEmptySet(t *testing.T) {
+	issuer, _ := NewKeySet(NewRSAKey("v1", _rsaKey(t)))
+
+	var empty atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if empty.Load() {
+			_, _ = w.Write([]byte(`{"keys":[{"kty":"RSA","kid":"broken"}]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(issuer.JWKS())
+	}))
+	defer server.Close()
+
+	remote, err := NewRemoteJWKS(context.Background(), &RemoteJWKSConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer remote.Close()
+
+	empty.Store(true)
+	if err := remote.Refresh(context.Background()); err == nil {
+		t.Errorf("expected the refresh to fail without valid keys")
+	}
+	token, _ := issuer.GenToken(map[string]any{"sub": "user-1"}, time.Minute)
+	if _, err := remote.VerifyToken(token); err != nil {
+		t.Errorf("expected the cached keys to be kept, got %v", err)
+	}
+}
+
+func _rsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	return key
+}
@@ -7,24 +7,42 @@ import (
 	"time"
 )
 
+type hmacSigner []byte
+
+func (s hmacSigner) Sign(claims jwt.Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s))
+}
+
+func (s hmacSigner) Keyfunc(token *jwt.Token) (any, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return []byte(s), nil
+}
+
 func GenToken(payload map[string]any, secretKey []byte, exp time.Duration) (string, error) {
+	return Sign(hmacSigner(secretKey), payload, exp)
+}
+
+func VerifyToken(tokenString string, secretKey []byte) (map[string]any, error) {
+	return Verify(hmacSigner(secretKey), tokenString)
+}
+
+// Sign generates a token with the given signer.
+// The `jti` claim is generated when missing, and `exp` and `iat` are always set.
+func Sign(signer Signer, payload map[string]any, exp time.Duration) (string, error) {
 	var claims jwt.MapClaims = payload
 	if _, ok := claims["jti"]; !ok {
 		claims["jti"] = uuid.NewString()
 	}
 	claims["exp"] = time.Now().Add(exp).Unix()
 	claims["iat"] = time.Now().Unix()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secretKey)
+	return signer.Sign(claims)
 }
 
-func VerifyToken(tokenString string, secretKey []byte) (map[string]any, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return secretKey, nil
-	})
+// Verify parses and validates a token with the key resolved by the verifier and returns its claims.
+func Verify(verifier Verifier, tokenString string) (map[string]any, error) {
+	token, err := jwt.Parse(tokenString, verifier.Keyfunc)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,174 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"testing"
+	"time"
+)
+
+func TestGenTokenAndVerifyToken(t *testing.T) {
+	secret := []byte("mySecret")
+	token, err := GenToken(map[string]any{"sub": "user-1"}, secret, time.Minute)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	claims, err := VerifyToken(token, secret)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claims["sub"] != "user-1" {
+		t.Errorf("expected sub to be user-1, got %v", claims["sub"])
+	}
+	if _, err := VerifyToken(token, []byte("wrongSecret")); err == nil {
+		t.Errorf("expected error for wrong secret, got nil")
+	}
+}
+
+func TestKeySetAsymmetricKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ecdsa key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ed25519 key: %v", err)
+	}
+	esKey, err := NewECDSAKey("es", ecKey)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, key := range []*Key{NewRSAKey("rs", rsaKey), esKey, NewEd25519Key("ed", edKey)} {
+		ks, err := NewKeySet(key)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		token, err := ks.GenToken(map[string]any{"sub": "user-1"}, time.Minute)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", key.Method.Alg(), err)
+		}
+		claims, err := ks.VerifyToken(token)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", key.Method.Alg(), err)
+		}
+		if claims["sub"] != "user-1" {
+			t.Errorf("%s: expected sub to be user-1, got %v", key.Method.Alg(), claims["sub"])
+		}
+
+		// A verify only set built from the public key must accept the token.
+		public, err := NewPublicKey(key.ID, key.VerifyKey)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", key.Method.Alg(), err)
+		}
+		verifier, _ := NewKeySet(nil, public)
+		if _, err := verifier.VerifyToken(token); err != nil {
+			t.Errorf("%s: expected public key to verify token, got %v", key.Method.Alg(), err)
+		}
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	ks, _ := NewKeySet(NewHMACKey("v1", []byte("first")))
+	oldToken, err := ks.GenToken(map[string]any{"sub": "user-1"}, time.Minute)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := ks.Rotate(NewHMACKey("v2", []byte("second"))); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	newToken, _ := ks.GenToken(map[string]any{"sub": "user-1"}, time.Minute)
+
+	// Tokens signed before the rotation keep verifying
+	if _, err := ks.VerifyToken(oldToken); err != nil {
+		t.Errorf("expected old token to verify, got %v", err)
+	}
+	if _, err := ks.VerifyToken(newToken); err != nil {
+		t.Errorf("expected new token to verify, got %v", err)
+	}
+
+	// Once the old key is removed, its tokens are rejected
+	if err := ks.Remove("v1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := ks.VerifyToken(oldToken); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+	if err := ks.Remove("v2"); err == nil {
+		t.Errorf("expected error when removing the signing key, got nil")
+	}
+}
+
+func TestKeySetRejectsAlgorithmMismatch(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	ks, _ := NewKeySet(NewRSAKey("shared", rsaKey))
+
+	// An HMAC token claiming the kid of an RSA key must not verify
+	forged, _ := NewKeySet(NewHMACKey("shared", []byte("attacker")))
+	token, _ := forged.GenToken(map[string]any{"sub": "admin"}, time.Minute)
+	if _, err := ks.VerifyToken(token); !errors.Is(err, ErrUnexpectedMethod) {
+		t.Errorf("expected ErrUnexpectedMethod, got %v", err)
+	}
+}
+
+type testClaims struct {
+	RegisteredClaims
+	Roles    []string `json:"roles"`
+	TenantID string   `json:"tenant_id"`
+}
+
+func TestTypedClaims(t *testing.T) {
+	secret := []byte("mySecret")
+	claims := &testClaims{Roles: []string{"admin"}, TenantID: "tenant-1"}
+	claims.Subject = "user-1"
+	claims.Issuer = "auth"
+	token, err := GenTokenWithClaims(claims, secret, time.Minute)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	verified, err := VerifyTokenWithClaims[testClaims](token, secret, &VerifyOptions{Issuer: "auth"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if verified.Subject != "user-1" || verified.TenantID != "tenant-1" || len(verified.Roles) != 1 {
+		t.Errorf("unexpected claims: %+v", verified)
+	}
+	if verified.ID == "" {
+		t.Errorf("expected jti to be generated")
+	}
+
+	if _, err := VerifyTokenWithClaims[testClaims](token, secret, &VerifyOptions{Issuer: "other"}); err == nil {
+		t.Errorf("expected error for wrong issuer, got nil")
+	}
+	if _, err := VerifyTokenWithClaims[testClaims](token, secret, &VerifyOptions{Audience: "api"}); err == nil {
+		t.Errorf("expected error for missing audience, got nil")
+	}
+}
+
+func TestTypedClaimsLeeway(t *testing.T) {
+	secret := []byte("mySecret")
+	claims := &testClaims{}
+	claims.NotBefore = jwt.NewNumericDate(time.Now().Add(5 * time.Second))
+	token, _ := GenTokenWithClaims(claims, secret, time.Minute)
+
+	if _, err := VerifyTokenWithClaims[testClaims](token, secret, nil); err == nil {
+		t.Errorf("expected error for token not valid yet, got nil")
+	}
+	if _, err := VerifyTokenWithClaims[testClaims](token, secret, &VerifyOptions{Leeway: 10 * time.Second}); err != nil {
+		t.Errorf("expected leeway to accept the token, got %v", err)
+	}
+}
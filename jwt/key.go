@@ -0,0 +1,163 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a signing or verification key identified by its `kid`.
+// A Key without a SignKey can only be used to verify tokens.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	SignKey   any
+	VerifyKey any
+}
+
+// CanSign reports whether the key holds the private part needed to sign tokens.
+func (k *Key) CanSign() bool {
+	return k.SignKey != nil
+}
+
+// NewHMACKey creates an HS256 key from a shared secret.
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		SignKey:   secret,
+		VerifyKey: secret,
+	}
+}
+
+// NewRSAKey creates an RS256 key from an RSA private key.
+func NewRSAKey(id string, key *rsa.PrivateKey) *Key {
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodRS256,
+		SignKey:   key,
+		VerifyKey: &key.PublicKey,
+	}
+}
+
+// NewECDSAKey creates an ES256, ES384 or ES512 key from an ECDSA private key,
+// depending on its curve.
+func NewECDSAKey(id string, key *ecdsa.PrivateKey) (*Key, error) {
+	method, err := _ecdsaMethod(key.Curve)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		ID:        id,
+		Method:    method,
+		SignKey:   key,
+		VerifyKey: &key.PublicKey,
+	}, nil
+}
+
+// NewEd25519Key creates an EdDSA key from an Ed25519 private key.
+func NewEd25519Key(id string, key ed25519.PrivateKey) *Key {
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodEdDSA,
+		SignKey:   key,
+		VerifyKey: key.Public(),
+	}
+}
+
+// NewPublicKey creates a verification only key from an RSA, ECDSA or Ed25519 public key.
+func NewPublicKey(id string, key crypto.PublicKey) (*Key, error) {
+	var method jwt.SigningMethod
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		m, err := _ecdsaMethod(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		method = m
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported public key type: %T", key)
+	}
+	return &Key{
+		ID:        id,
+		Method:    method,
+		VerifyKey: key,
+	}, nil
+}
+
+// NewKeyFromPEM creates a key from a PEM encoded private or public key.
+// PKCS#1, PKCS#8, SEC 1 (EC) private keys and PKIX public keys are supported.
+func NewKeyFromPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewPublicKey(id, key)
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewPublicKey(id, key)
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewRSAKey(id, key), nil
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewECDSAKey(id, key)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return NewRSAKey(id, k), nil
+		case *ecdsa.PrivateKey:
+			return NewECDSAKey(id, k)
+		case ed25519.PrivateKey:
+			return NewEd25519Key(id, k), nil
+		default:
+			return nil, fmt.Errorf("unsupported private key type: %T", key)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+}
+
+func _ecdsaMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	default:
+		return nil, fmt.Errorf("unsupported elliptic curve: %s", curve.Params().Name)
+	}
+}
@@ -0,0 +1,159 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"sync"
+	"time"
+)
+
+var (
+	ErrKeyNotFound      = errors.New("jwt: key not found")
+	ErrNoSigningKey     = errors.New("jwt: no signing key")
+	ErrUnexpectedMethod = errors.New("jwt: unexpected signing method")
+)
+
+// Signer signs the given claims and returns the compact token.
+type Signer interface {
+	Sign(claims jwt.Claims) (string, error)
+}
+
+// Verifier resolves the key used to verify a parsed token.
+// Its Keyfunc is passed to jwt.Parse.
+type Verifier interface {
+	Keyfunc(token *jwt.Token) (any, error)
+}
+
+// KeySet holds one signing key and any number of verification keys.
+// It supports key rotation: tokens are always signed with the current signing key
+// and carry its `kid`, while tokens signed with previous keys keep verifying
+// until those keys are removed from the set.
+type KeySet struct {
+	mu         sync.RWMutex
+	keys       map[string]*Key
+	signingKID string
+}
+
+// NewKeySet creates a KeySet signing with the given key.
+// Additional keys are only used for verification.
+func NewKeySet(signingKey *Key, verificationKeys ...*Key) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key)}
+	for _, key := range verificationKeys {
+		ks.Add(key)
+	}
+	if signingKey != nil {
+		if err := ks.Rotate(signingKey); err != nil {
+			return nil, err
+		}
+	}
+	return ks, nil
+}
+
+// Add registers a verification key, replacing any key with the same `kid`.
+func (ks *KeySet) Add(key *Key) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[key.ID] = key
+}
+
+// Rotate adds the key to the set and makes it the signing key.
+// The previous signing key stays available for verification.
+func (ks *KeySet) Rotate(key *Key) error {
+	if !key.CanSign() {
+		return fmt.Errorf("%w: key %s has no private part", ErrNoSigningKey, key.ID)
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[key.ID] = key
+	ks.signingKID = key.ID
+	return nil
+}
+
+// Remove deletes a verification key. The current signing key cannot be removed.
+func (ks *KeySet) Remove(kid string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if kid == ks.signingKID {
+		return fmt.Errorf("jwt: cannot remove the signing key %s", kid)
+	}
+	delete(ks.keys, kid)
+	return nil
+}
+
+// Lookup returns the key with the given `kid`.
+func (ks *KeySet) Lookup(kid string) (*Key, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// SigningKey returns the current signing key.
+func (ks *KeySet) SigningKey() (*Key, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	key, ok := ks.keys[ks.signingKID]
+	if !ok {
+		return nil, ErrNoSigningKey
+	}
+	return key, nil
+}
+
+// Keys returns all keys of the set.
+func (ks *KeySet) Keys() []*Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	keys := make([]*Key, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key, err := ks.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.SignKey)
+}
+
+// Keyfunc selects the verification key by the token `kid` header.
+// Tokens without a `kid` are verified with the current signing key.
+// The token algorithm must match the algorithm of the selected key.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var key *Key
+	if kid == "" {
+		k, err := ks.SigningKey()
+		if err != nil {
+			return nil, err
+		}
+		key = k
+	} else {
+		k, ok := ks.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+		}
+		key = k
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedMethod, token.Header["alg"])
+	}
+	return key.VerifyKey, nil
+}
+
+// GenToken generates a token like GenToken, signed with the current signing key of the set.
+func (ks *KeySet) GenToken(payload map[string]any, exp time.Duration) (string, error) {
+	return Sign(ks, payload, exp)
+}
+
+// VerifyToken verifies a token signed with any key of the set and returns its claims.
+func (ks *KeySet) VerifyToken(tokenString string) (map[string]any, error) {
+	return Verify(ks, tokenString)
+}
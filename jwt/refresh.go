@@ -0,0 +1,255 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrTokenRevoked        = errors.New("jwt: token has been revoked")
+	ErrRefreshTokenReused  = errors.New("jwt: refresh token reuse detected")
+	ErrUnexpectedTokenType = errors.New("jwt: unexpected token type")
+)
+
+const (
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
+type TokenManagerConfig struct {
+	// Signer signs the issued tokens, e.g. a KeySet.
+	Signer Signer
+
+	// Verifier verifies the tokens. Default is Signer when it also implements Verifier.
+	Verifier Verifier
+
+	// Redis is the client created by redis.NewConnection used to track refresh tokens.
+	Redis *redis.Client
+
+	// AccessTTL is the lifetime of access tokens.
+	// Default is 15 minutes.
+	AccessTTL time.Duration
+
+	// RefreshTTL is the lifetime of refresh tokens and of a token family.
+	// Default is 7 days.
+	RefreshTTL time.Duration
+
+	// KeyPrefix is the prefix of every Redis key.
+	// Default is "jwt:".
+	KeyPrefix string
+}
+
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+/*
+TokenManager issues access/refresh token pairs tracked in Redis.
+
+Every login starts a token family identified by the `fid` claim. Refresh tokens
+are single use: a refresh consumes the token and issues a new pair in the same
+family. Presenting an already consumed refresh token is treated as theft and
+revokes the whole family, including its access tokens.
+
+Redis keys:
+- <prefix>family:<fid>: the payload of the family, present while the family is active.
+- <prefix>refresh:<jti>: the family of a refresh token, present until it is used.
+- <prefix>denylist:<jti>: a revoked access token, present until it expires.
+*/
+type TokenManager struct {
+	config *TokenManagerConfig
+}
+
+func NewTokenManager(config *TokenManagerConfig) (*TokenManager, error) {
+	if config == nil {
+		return nil, errors.New("jwt: config cannot be nil")
+	}
+	if config.Signer == nil {
+		return nil, errors.New("jwt: signer cannot be nil")
+	}
+	if config.Redis == nil {
+		return nil, errors.New("jwt: redis client cannot be nil")
+	}
+	if config.Verifier == nil {
+		verifier, ok := config.Signer.(Verifier)
+		if !ok {
+			return nil, errors.New("jwt: verifier cannot be nil")
+		}
+		config.Verifier = verifier
+	}
+	if config.AccessTTL == 0 {
+		config.AccessTTL = 15 * time.Minute
+	}
+	if config.RefreshTTL == 0 {
+		config.RefreshTTL = 7 * 24 * time.Hour
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "jwt:"
+	}
+	return &TokenManager{config: config}, nil
+}
+
+// Issue starts a new token family for the payload and returns its first token pair.
+func (m *TokenManager) Issue(ctx context.Context, payload map[string]any) (*TokenPair, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	familyID := uuid.NewString()
+	if err := m.config.Redis.Set(ctx, m._familyKey(familyID), data, m.config.RefreshTTL).Err(); err != nil {
+		return nil, err
+	}
+	return m._issuePair(ctx, familyID, payload)
+}
+
+// Refresh consumes the refresh token and returns a new token pair of the same family.
+// Reusing a consumed refresh token revokes the family and returns ErrRefreshTokenReused.
+func (m *TokenManager) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := Verify(m.config.Verifier, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims["typ"] != refreshTokenType {
+		return nil, ErrUnexpectedTokenType
+	}
+	jti, _ := claims["jti"].(string)
+	familyID, _ := claims["fid"].(string)
+
+	err = m.config.Redis.GetDel(ctx, m._refreshKey(jti)).Err()
+	if errors.Is(err, redis.Nil) {
+		slog.WarnContext(ctx, "JWT: Refresh token reuse detected, revoking family", "fid", familyID, "jti", jti)
+		if err := m.RevokeFamily(ctx, familyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := m.config.Redis.Get(ctx, m._familyKey(familyID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrTokenRevoked
+	}
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	if err := m.config.Redis.Expire(ctx, m._familyKey(familyID), m.config.RefreshTTL).Err(); err != nil {
+		return nil, err
+	}
+	return m._issuePair(ctx, familyID, payload)
+}
+
+// VerifyAccessToken verifies an access token and checks that neither the token
+// nor its family has been revoked.
+func (m *TokenManager) VerifyAccessToken(ctx context.Context, accessToken string) (map[string]any, error) {
+	claims, err := Verify(m.config.Verifier, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims["typ"] != accessTokenType {
+		return nil, ErrUnexpectedTokenType
+	}
+	jti, _ := claims["jti"].(string)
+	familyID, _ := claims["fid"].(string)
+
+	pipe := m.config.Redis.Pipeline()
+	denied := pipe.Exists(ctx, m._denylistKey(jti))
+	family := pipe.Exists(ctx, m._familyKey(familyID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	if denied.Val() > 0 || family.Val() == 0 {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// Revoke adds an access token to the denylist until it expires.
+func (m *TokenManager) Revoke(ctx context.Context, accessToken string) error {
+	claims, err := Verify(m.config.Verifier, accessToken)
+	if err != nil {
+		return err
+	}
+	jti, _ := claims["jti"].(string)
+	exp, err := jwt.MapClaims(claims).GetExpirationTime()
+	if err != nil || exp == nil {
+		return fmt.Errorf("jwt: invalid exp claim: %w", err)
+	}
+	ttl := time.Until(exp.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return m.config.Redis.Set(ctx, m._denylistKey(jti), 1, ttl).Err()
+}
+
+// RevokeFamily revokes every access and refresh token of the family, e.g. on logout.
+func (m *TokenManager) RevokeFamily(ctx context.Context, familyID string) error {
+	return m.config.Redis.Del(ctx, m._familyKey(familyID)).Err()
+}
+
+func (m *TokenManager) _issuePair(ctx context.Context, familyID string, payload map[string]any) (*TokenPair, error) {
+	now := time.Now()
+
+	access := make(map[string]any, len(payload)+3)
+	for k, v := range payload {
+		access[k] = v
+	}
+	access["jti"] = uuid.NewString()
+	access["typ"] = accessTokenType
+	access["fid"] = familyID
+	accessToken, err := Sign(m.config.Signer, access, m.config.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshID := uuid.NewString()
+	refresh := map[string]any{
+		"jti": refreshID,
+		"typ": refreshTokenType,
+		"fid": familyID,
+	}
+	if sub, ok := payload["sub"]; ok {
+		refresh["sub"] = sub
+	}
+	refreshToken, err := Sign(m.config.Signer, refresh, m.config.RefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.config.Redis.Set(ctx, m._refreshKey(refreshID), familyID, m.config.RefreshTTL).Err(); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		AccessExpiresAt:  now.Add(m.config.AccessTTL),
+		RefreshExpiresAt: now.Add(m.config.RefreshTTL),
+	}, nil
+}
+
+func (m *TokenManager) _familyKey(familyID string) string {
+	return m.config.KeyPrefix + "family:" + familyID
+}
+
+func (m *TokenManager) _refreshKey(jti string) string {
+	return m.config.KeyPrefix + "refresh:" + jti
+}
+
+func (m *TokenManager) _denylistKey(jti string) string {
+	return m.config.KeyPrefix + "denylist:" + jti
+}
@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"testing"
+)
+
+func _tokenManager(t *testing.T) *TokenManager {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ks, _ := NewKeySet(NewHMACKey("v1", []byte("secret")))
+	m, err := NewTokenManager(&TokenManagerConfig{Signer: ks, Redis: client})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestTokenManagerRefreshRotation(t *testing.T) {
+	m := _tokenManager(t)
+	ctx := context.Background()
+
+	first, err := m.Issue(ctx, map[string]any{"sub": "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("expected the refresh to succeed, got %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Errorf("expected a new token pair")
+	}
+	claims, err := m.VerifyAccessToken(ctx, second.AccessToken)
+	if err != nil || claims["sub"] != "user-1" {
+		t.Errorf("expected the payload to be kept, got %v %v", claims, err)
+	}
+	if _, err := m.Refresh(ctx, second.AccessToken); !errors.Is(err, ErrUnexpectedTokenType) {
+		t.Errorf("expected an access token to be rejected as refresh token, got %v", err)
+	}
+	if _, err := m.VerifyAccessToken(ctx, second.RefreshToken); !errors.Is(err, ErrUnexpectedTokenType) {
+		t.Errorf("expected a refresh token to be rejected as access token, got %v", err)
+	}
+}
+
+func TestTokenManagerRefreshReuse(t *testing.T) {
+	m := _tokenManager(t)
+	ctx := context.Background()
+
+	first, _ := m.Issue(ctx, map[string]any{"sub": "user-1"})
+	second, err := m.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := m.Issue(ctx, map[string]any{"sub": "user-2"})
+
+	if _, err := m.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("expected the reuse to be detected, got %v", err)
+	}
+	// The whole family is revoked, including the tokens issued after the stolen one
+	if _, err := m.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("expected the refresh token of the family to be revoked, got %v", err)
+	}
+	if _, err := m.VerifyAccessToken(ctx, second.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("expected the access token of the family to be revoked, got %v", err)
+	}
+	if _, err := m.VerifyAccessToken(ctx, other.AccessToken); err != nil {
+		t.Errorf("other families must not be revoked, got %v", err)
+	}
+}
+
+func TestTokenManagerRevoke(t *testing.T) {
+	m := _tokenManager(t)
+	ctx := context.Background()
+
+	pair, _ := m.Issue(ctx, map[string]any{"sub": "user-1"})
+	if err := m.Revoke(ctx, pair.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.VerifyAccessToken(ctx, pair.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("expected the denylisted token to be rejected, got %v", err)
+	}
+	// Revoking an access token does not end the session
+	next, err := m.Refresh(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("expected the refresh to succeed, got %v", err)
+	}
+	if _, err := m.VerifyAccessToken(ctx, next.AccessToken); err != nil {
+		t.Errorf("expected the new access token to be valid, got %v", err)
+	}
+}
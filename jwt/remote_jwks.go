@@ -0,0 +1,210 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxJWKSSize is the maximum size of a JWKS response, larger documents are truncated and rejected.
+const maxJWKSSize = 1 << 20
+
+type RemoteJWKSConfig struct {
+	// URL is the JWKS endpoint of the issuer, e.g. "https://auth.example.com/.well-known/jwks.json".
+	URL string
+
+	// RefreshInterval is the interval at which the keys are refreshed in the background.
+	// Default is 15 minutes.
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval limits how often an unknown `kid` may trigger an immediate refresh,
+	// counted from the last attempt, so forged `kid`s do not hammer a failing endpoint.
+	// Default is 30 seconds.
+	MinRefreshInterval time.Duration
+
+	// Timeout is the timeout of a single JWKS request.
+	// Default is 10 seconds.
+	Timeout time.Duration
+
+	// HTTPClient is the client used to fetch the keys.
+	// Default is http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// RemoteJWKS is a Verifier using the public keys published by a remote issuer.
+// Keys are cached and refreshed in the background, and a token with an unknown `kid`
+// triggers an immediate refresh so newly rotated keys are picked up.
+type RemoteJWKS struct {
+	config *RemoteJWKSConfig
+
+	mu   sync.RWMutex
+	keys map[string]*Key
+
+	// refreshMu serializes the fetches and guards lastAttempt
+	refreshMu   sync.Mutex
+	lastAttempt time.Time
+	cancel      context.CancelFunc
+}
+
+// NewRemoteJWKS fetches the keys from the configured URL and starts refreshing them
+// in the background until ctx is cancelled or Close is called.
+func NewRemoteJWKS(ctx context.Context, config *RemoteJWKSConfig) (*RemoteJWKS, error) {
+	if config == nil || config.URL == "" {
+		return nil, errors.New("jwt: remote JWKS URL cannot be empty")
+	}
+	if config.RefreshInterval == 0 {
+		config.RefreshInterval = 15 * time.Minute
+	}
+	if config.MinRefreshInterval == 0 {
+		config.MinRefreshInterval = 30 * time.Second
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+
+	r := &RemoteJWKS{
+		config: config,
+		keys:   make(map[string]*Key),
+	}
+	if err := r.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	go r._refreshLoop(ctx)
+	return r, nil
+}
+
+// Close stops the background refresh.
+func (r *RemoteJWKS) Close() {
+	r.cancel()
+}
+
+// Refresh fetches the keys from the issuer and replaces the cached keys.
+func (r *RemoteJWKS) Refresh(ctx context.Context) error {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+	return r._refresh(ctx)
+}
+
+// _refreshOnMiss refreshes the keys for an unknown `kid`, unless an attempt was made less
+// than MinRefreshInterval ago. Concurrent misses wait for the running attempt and do not
+// start their own.
+func (r *RemoteJWKS) _refreshOnMiss(ctx context.Context) error {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+	if time.Since(r.lastAttempt) < r.config.MinRefreshInterval {
+		return nil
+	}
+	return r._refresh(ctx)
+}
+
+// _refresh fetches the keys, refreshMu must be held.
+func (r *RemoteJWKS) _refresh(ctx context.Context) error {
+	r.lastAttempt = time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.config.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := r.config.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("jwt: failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwt: failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var jwks JWKS
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSSize)).Decode(&jwks); err != nil {
+		return fmt.Errorf("jwt: failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]*Key, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "JWT: Skipping invalid JWK", "kid", jwk.Kid, "error", err.Error())
+			continue
+		}
+		keys[key.ID] = key
+	}
+	// Keep the cached keys rather than failing every token on a broken response
+	if len(keys) == 0 {
+		return errors.New("jwt: JWKS contains no valid signing key")
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.mu.Unlock()
+	return nil
+}
+
+// Keyfunc selects the verification key by the token `kid` header.
+func (r *RemoteJWKS) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("%w: token has no kid", ErrKeyNotFound)
+	}
+
+	key, ok := r._lookup(kid)
+	if !ok {
+		if err := r._refreshOnMiss(context.Background()); err != nil {
+			slog.Warn("JWT: Failed to refresh JWKS", "error", err.Error())
+		}
+		key, ok = r._lookup(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedMethod, token.Header["alg"])
+	}
+	return key.VerifyKey, nil
+}
+
+// VerifyToken verifies a token signed by the remote issuer and returns its claims.
+func (r *RemoteJWKS) VerifyToken(tokenString string) (map[string]any, error) {
+	return Verify(r, tokenString)
+}
+
+func (r *RemoteJWKS) _lookup(kid string) (*Key, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+func (r *RemoteJWKS) _refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(r.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil {
+				slog.WarnContext(ctx, "JWT: Failed to refresh JWKS", "url", r.config.URL, "error", err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+)
+
+// FanoutHandler sends every record to all of its handlers, each one applying its own level.
+type FanoutHandler struct {
+	handlers []slog.Handler
+}
+
+func NewFanoutHandler(handlers ...slog.Handler) *FanoutHandler {
+	return &FanoutHandler{handlers: handlers}
+}
+
+func (h *FanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *FanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, r.Level) {
+			err = errors.Join(err, handler.Handle(ctx, r.Clone()))
+		}
+	}
+	return err
+}
+
+func (h *FanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &FanoutHandler{handlers: handlers}
+}
+
+func (h *FanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &FanoutHandler{handlers: handlers}
+}
+
+/*
+Tee adds the handler to the default logger set up by SetupLogger and returns the new default logger.
+Records keep going to the existing handler and are also sent to the given one, both receiving
+the context values configured with Config.ContextKeys.
+
+When SetupLogger was not called, the records are written as text to os.Stderr besides the handler,
+since the standard default handler can not be combined with another one.
+*/
+func Tee(handler slog.Handler) *slog.Logger {
+	var tee slog.Handler
+	if current, ok := slog.Default().Handler().(*ContextHandler); ok {
+		tee = &ContextHandler{NewFanoutHandler(current.Handler, handler), current.contextKeys}
+	} else {
+		tee = NewFanoutHandler(slog.NewTextHandler(os.Stderr, nil), handler)
+	}
+	logger := slog.New(tee)
+	slog.SetDefault(logger)
+	return logger
+}
@@ -3,6 +3,7 @@ package logger
 import (
 	"context"
 	"github.com/ppabimanyu/compage/logger/prettyslog"
+	"github.com/ppabimanyu/compage/reqctx"
 	"gopkg.in/natefinch/lumberjack.v2"
 	"io"
 	"log/slog"
@@ -39,9 +40,12 @@ type ContextHandler struct {
 }
 
 // Handle overrides the default Handle method to add context values.
+// The request metadata keys of the reqctx package, e.g. "request_id", are read with their typed keys.
 func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
 	for _, key := range h.contextKeys {
-		if value := ctx.Value(key); value != nil {
+		if value, ok := reqctx.Lookup(ctx, key); ok {
+			r.AddAttrs(slog.String(key, value))
+		} else if value := ctx.Value(key); value != nil {
 			r.AddAttrs(slog.Any(key, value))
 		}
 	}
@@ -77,7 +81,8 @@ type Config struct {
 
 	// contextKeys is a list of keys to extract from the context
 	// and add to the log record.
-	// e.g. "request_id", "trace_id", "user_id", etc.
+	// e.g. "request_id", "trace_id", "tenant_id", etc. for the reqctx metadata,
+	// or any other string key.
 	ContextKeys []string
 }
 
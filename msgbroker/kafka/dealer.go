@@ -3,12 +3,15 @@ package kafka
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ppabimanyu/compage/reqctx"
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/kafka-go/sasl"
 	"github.com/segmentio/kafka-go/sasl/plain"
@@ -17,8 +20,10 @@ import (
 
 const (
 	TraceIDHeaderKey = "Trace-Id"
-	TraceIDCtxKey    = "trace_id"
-	RequestIDCtxKey  = "request_id"
+	// TraceIDCtxKey and RequestIDCtxKey are the reqctx keys set on the consumer context,
+	// read them with reqctx.TraceIDFrom and reqctx.RequestIDFrom.
+	TraceIDCtxKey   = reqctx.TraceIDKey
+	RequestIDCtxKey = reqctx.RequestIDKey
 )
 
 type Config struct {
@@ -128,14 +133,33 @@ func NewDealerWithContext(ctx context.Context, config *Config) *Dealer {
 	return dealer
 }
 
+func (d *Dealer) _dialer() *kafka.Dialer {
+	return &kafka.Dialer{
+		SASLMechanism: d.Mechanism,
+		TLS:           d.TlsConfig,
+	}
+}
+
+// Ping checks that at least one of the configured brokers is reachable.
+// It returns the joined dial errors when none of them can be reached.
+func (d *Dealer) Ping(ctx context.Context) error {
+	var errs error
+	for _, broker := range d.Config.Brokers {
+		conn, err := d._dialer().DialContext(ctx, "tcp", broker)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", broker, err))
+			continue
+		}
+		return conn.Close()
+	}
+	return errs
+}
+
 func (d *Dealer) DefaultReader(topic string, groupID ...string) *kafka.Reader {
 	config := kafka.ReaderConfig{
 		Brokers: d.Config.Brokers,
 		Topic:   topic,
-		Dialer: &kafka.Dialer{
-			SASLMechanism: d.Mechanism,
-			TLS:           d.TlsConfig,
-		},
+		Dialer:  d._dialer(),
 		ErrorLogger: kafka.LoggerFunc(func(s string, i ...any) {
 			slog.Error(s, i...)
 		}),
@@ -188,7 +212,7 @@ func (d *Dealer) DefaultConsumer(consumerFunc ConsumerFunc, topic string, groupI
 			return
 		default:
 			requestID := uuid.New().String()
-			ctx := context.WithValue(d.Context, RequestIDCtxKey, requestID)
+			ctx := reqctx.WithRequestID(d.Context, requestID)
 
 			message, err := reader.FetchMessage(ctx)
 			if err != nil {
@@ -196,6 +220,7 @@ func (d *Dealer) DefaultConsumer(consumerFunc ConsumerFunc, topic string, groupI
 				continue
 			}
 
+			ctx, span := _startConsumeSpan(ctx, message, reader.Config().GroupID)
 			traceID := requestID
 			for _, h := range message.Headers {
 				if strings.ToLower(string(h.Key)) == strings.ToLower(TraceIDHeaderKey) {
@@ -203,24 +228,34 @@ func (d *Dealer) DefaultConsumer(consumerFunc ConsumerFunc, topic string, groupI
 					break
 				}
 			}
-			ctx = context.WithValue(ctx, TraceIDCtxKey, traceID)
+			if span.SpanContext().IsValid() {
+				traceID = span.SpanContext().TraceID().String()
+			}
+			ctx = reqctx.WithTraceID(ctx, traceID)
 
 			slog.InfoContext(ctx, "Kafka: Received message", "topic", message.Topic, "key", string(message.Key), "value", string(message.Value))
-			if err := consumerFunc(ctx, message); err != nil {
+			err = consumerFunc(ctx, message)
+			if err != nil {
 				slog.ErrorContext(ctx, "Kafka: Failed to process message", "topic", message.Topic, "key", string(message.Key), "value", string(message.Value), "error", err.Error())
 			} else {
-				if err := reader.CommitMessages(ctx, message); err != nil {
+				if err = reader.CommitMessages(ctx, message); err != nil {
 					slog.ErrorContext(ctx, "Kafka: Failed to commit message", "topic", message.Topic, "key", string(message.Key), "value", string(message.Value), "error", err.Error())
 				} else {
 					slog.InfoContext(ctx, "Kafka: Committed message", "topic", message.Topic, "key", string(message.Key), "value", string(message.Value))
 				}
 			}
+			_endSpan(span, err)
 		}
 	}
 }
 
+// DefaultPublisher writes the messages to the topic within a producer span, injecting the
+// W3C trace context and baggage of ctx into the message headers. The messages given are not modified.
 func (d *Dealer) DefaultPublisher(ctx context.Context, topic string, data ...kafka.Message) error {
+	ctx, span, data := _startPublishSpan(ctx, topic, data)
 	writer := d.DefaultWriter(topic)
 	defer writer.Close()
-	return writer.WriteMessages(ctx, data...)
+	err := writer.WriteMessages(ctx, data...)
+	_endSpan(span, err)
+	return err
 }
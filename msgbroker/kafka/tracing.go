@@ -0,0 +1,126 @@
+package kafka
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ppabimanyu/compage/reqctx"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the spans started by the kafka package.
+const TracerName = "github.com/ppabimanyu/compage/msgbroker/kafka"
+
+// headerCarrier adapts the message headers to propagation.TextMapCarrier.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (h headerCarrier) Get(key string) string {
+	for _, header := range *h.headers {
+		if strings.EqualFold(header.Key, key) {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (h headerCarrier) Set(key, value string) {
+	for i, header := range *h.headers {
+		if strings.EqualFold(header.Key, key) {
+			(*h.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*h.headers = append(*h.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, len(*h.headers))
+	for i, header := range *h.headers {
+		keys[i] = header.Key
+	}
+	return keys
+}
+
+// _startPublishSpan starts the producer span of a publish and injects its trace context
+// and baggage into the headers of copies of the messages, along with the legacy Trace-Id header.
+func _startPublishSpan(ctx context.Context, topic string, messages []kafka.Message) (context.Context, trace.Span, []kafka.Message) {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationTypePublish,
+		semconv.MessagingOperationName("publish"),
+		semconv.MessagingDestinationName(topic),
+	}
+	if len(messages) > 1 {
+		attrs = append(attrs, semconv.MessagingBatchMessageCount(len(messages)))
+	}
+	ctx, span := otel.Tracer(TracerName).Start(ctx, "publish "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attrs...),
+	)
+
+	// Without a tracer provider the span is not valid, keep the legacy trace ID
+	traceID := reqctx.TraceIDFrom(ctx)
+	if span.SpanContext().IsValid() {
+		traceID = span.SpanContext().TraceID().String()
+	}
+
+	propagator := otel.GetTextMapPropagator()
+	messages = slices.Clone(messages)
+	for i := range messages {
+		messages[i].Headers = slices.Clone(messages[i].Headers)
+		carrier := headerCarrier{headers: &messages[i].Headers}
+		propagator.Inject(ctx, carrier)
+		if carrier.Get(TraceIDHeaderKey) == "" && traceID != "" {
+			carrier.Set(TraceIDHeaderKey, traceID)
+		}
+	}
+	return ctx, span, messages
+}
+
+// _startConsumeSpan extracts the trace context and baggage of the message and starts the
+// consumer span, as a child of the producer span and linked to it.
+func _startConsumeSpan(ctx context.Context, message kafka.Message, groupID string) (context.Context, trace.Span) {
+	headers := message.Headers
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier{headers: &headers})
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationTypeDeliver,
+		semconv.MessagingOperationName("process"),
+		semconv.MessagingDestinationName(message.Topic),
+		semconv.MessagingDestinationPartitionID(strconv.Itoa(message.Partition)),
+		semconv.MessagingKafkaMessageOffset(int(message.Offset)),
+		semconv.MessagingMessageBodySize(len(message.Value)),
+	}
+	if groupID != "" {
+		attrs = append(attrs, semconv.MessagingKafkaConsumerGroup(groupID))
+	}
+	if len(message.Key) > 0 {
+		attrs = append(attrs, semconv.MessagingKafkaMessageKey(string(message.Key)))
+	}
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs...),
+	}
+	if producer := trace.SpanContextFromContext(ctx); producer.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: producer}))
+	}
+	return otel.Tracer(TracerName).Start(ctx, "process "+message.Topic, opts...)
+}
+
+func _endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	defer provider.Shutdown(context.Background())
+
+	member, _ := baggage.NewMember("tenant", "acme")
+	bag, _ := baggage.New(member)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	original := []kafka.Message{{Topic: "orders", Value: []byte("{}")}}
+	_, publishSpan, messages := _startPublishSpan(ctx, "orders", original)
+	publishSpan.End()
+	if len(original[0].Headers) != 0 {
+		t.Errorf("the original message headers were modified")
+	}
+
+	consumeCtx, consumeSpan := _startConsumeSpan(context.Background(), messages[0], "billing")
+	consumeSpan.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	producer, consumer := spans[0], spans[1]
+	if producer.SpanKind() != trace.SpanKindProducer || consumer.SpanKind() != trace.SpanKindConsumer {
+		t.Errorf("unexpected span kinds %v and %v", producer.SpanKind(), consumer.SpanKind())
+	}
+	if consumer.Parent().SpanID() != producer.SpanContext().SpanID() {
+		t.Errorf("the consumer span is not a child of the producer span")
+	}
+	if links := consumer.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != producer.SpanContext().SpanID() {
+		t.Errorf("the consumer span is not linked to the producer span")
+	}
+	if got := baggage.FromContext(consumeCtx).Member("tenant").Value(); got != "acme" {
+		t.Errorf("baggage was not propagated, got %q", got)
+	}
+	if got := (headerCarrier{headers: &messages[0].Headers}).Get(TraceIDHeaderKey); got != producer.SpanContext().TraceID().String() {
+		t.Errorf("unexpected legacy trace ID header %q", got)
+	}
+}
@@ -4,16 +4,20 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ppabimanyu/compage/reqctx"
 	"github.com/rabbitmq/amqp091-go"
 )
 
 const (
 	TraceIDHeaderKey = "Trace-Id"
-	TraceIDCtxKey    = "trace_id"
-	RequestIDCtxKey  = "request_id"
+	// TraceIDCtxKey and RequestIDCtxKey are the reqctx keys set on the consumer context,
+	// read them with reqctx.TraceIDFrom and reqctx.RequestIDFrom.
+	TraceIDCtxKey   = reqctx.TraceIDKey
+	RequestIDCtxKey = reqctx.RequestIDKey
 )
 
 type Config struct {
@@ -72,8 +76,38 @@ func NewDealerWithContext(ctx context.Context, config *Config) *Dealer {
 	return dealer
 }
 
+func (d *Dealer) _url() string {
+	return fmt.Sprintf("amqp://%s:%s@%s:%d/%s", d.config.Username, d.config.Password, d.config.Host, d.config.Port, d.config.VHost)
+}
+
+// Ping opens and immediately closes a connection to the RabbitMQ server
+// to check that it is reachable and accepts the configured credentials.
+func (d *Dealer) Ping(ctx context.Context) error {
+	conn, err := amqp091.DialConfig(d._url(), amqp091.Config{
+		Locale: "en_US",
+		Dial: func(network, addr string) (net.Conn, error) {
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			if deadline, ok := ctx.Deadline(); ok {
+				if err := conn.SetDeadline(deadline); err != nil {
+					conn.Close()
+					return nil, err
+				}
+			}
+			return conn, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
 func (d *Dealer) CreateConnection() *amqp091.Channel {
-	conn, err := amqp091.Dial(fmt.Sprintf("amqp://%s:%s@%s:%d/%s", d.config.Username, d.config.Password, d.config.Host, d.config.Port, d.config.VHost))
+	conn, err := amqp091.Dial(d._url())
 	if err != nil {
 		slog.Error("RabbitMQ: Failed to connect to RabbitMQ", "error", err.Error())
 		return nil
@@ -117,30 +151,37 @@ func (d *Dealer) DefaultConsumer(consumerFunc ConsumerFunc, queue string, consum
 
 	for message := range consumer {
 		requestID := uuid.New().String()
+		ctx := reqctx.WithRequestID(d.ctx, requestID)
+		ctx, span := _startConsumeSpan(ctx, queue, message)
 		traceID := requestID
-		if val, ok := message.Headers[TraceIDHeaderKey]; ok {
-			traceID = val.(string)
+		if val, ok := message.Headers[TraceIDHeaderKey].(string); ok && val != "" {
+			traceID = val
 		}
-		ctx := context.WithValue(d.ctx, RequestIDCtxKey, requestID)
-		ctx = context.WithValue(ctx, TraceIDCtxKey, traceID)
+		if span.SpanContext().IsValid() {
+			traceID = span.SpanContext().TraceID().String()
+		}
+		ctx = reqctx.WithTraceID(ctx, traceID)
 
 		slog.InfoContext(ctx, "RabbitMQ: Received message", "queue", queue, "body", string(message.Body))
-		err := consumerFunc(d.ctx, message)
+		err := consumerFunc(ctx, message)
 		if err != nil {
 			slog.ErrorContext(ctx, "RabbitMQ: Failed to process message", "queue", queue, "body", string(message.Body), "error", err.Error())
 			if err := message.Nack(false, false); err != nil {
 				slog.ErrorContext(ctx, "RabbitMQ: Failed to Nack message", "queue", queue, "body", string(message.Body), "error", err.Error())
 			}
 		} else {
-			if err := message.Ack(false); err != nil {
+			if err = message.Ack(false); err != nil {
 				slog.ErrorContext(ctx, "RabbitMQ: Failed to Ack message", "queue", queue, "body", string(message.Body), "error", err.Error())
 			} else {
 				slog.InfoContext(ctx, "RabbitMQ: Acknowledged message", "queue", queue, "body", string(message.Body))
 			}
 		}
+		_endSpan(span, err)
 	}
 }
 
+// DefaultPublisher publishes the messages, each within a producer span, injecting the
+// W3C trace context and baggage of ctx into the message headers. The messages given are not modified.
 func (d *Dealer) DefaultPublisher(ctx context.Context, exchange, routingKey string, data ...amqp091.Publishing) error {
 	conn := d.CreateConnection()
 	if conn == nil {
@@ -149,7 +190,10 @@ func (d *Dealer) DefaultPublisher(ctx context.Context, exchange, routingKey stri
 	defer conn.Close()
 
 	for _, message := range data {
-		if err := conn.PublishWithContext(ctx, exchange, routingKey, false, false, message); err != nil {
+		msgCtx, span, message := _startPublishSpan(ctx, exchange, routingKey, message)
+		err := conn.PublishWithContext(msgCtx, exchange, routingKey, false, false, message)
+		_endSpan(span, err)
+		if err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,131 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/ppabimanyu/compage/reqctx"
+	"github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the spans started by the rabbitmq package.
+const TracerName = "github.com/ppabimanyu/compage/msgbroker/rabbitmq"
+
+// headerCarrier adapts the message headers to propagation.TextMapCarrier.
+type headerCarrier amqp091.Table
+
+func (h headerCarrier) Get(key string) string {
+	value, ok := h[key]
+	if !ok {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = value
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// _destinationName returns the exchange name following the semantic conventions,
+// where the default exchange is named "amq.default".
+func _destinationName(exchange string) string {
+	if exchange == "" {
+		return "amq.default"
+	}
+	return exchange
+}
+
+// _startPublishSpan starts the producer span of a message and injects its trace context
+// and baggage into a copy of the message headers, along with the legacy Trace-Id header.
+func _startPublishSpan(ctx context.Context, exchange, routingKey string, message amqp091.Publishing) (context.Context, trace.Span, amqp091.Publishing) {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingOperationTypePublish,
+		semconv.MessagingOperationName("publish"),
+		semconv.MessagingDestinationName(_destinationName(exchange)),
+		semconv.MessagingMessageBodySize(len(message.Body)),
+	}
+	if routingKey != "" {
+		attrs = append(attrs, semconv.MessagingRabbitmqDestinationRoutingKey(routingKey))
+	}
+	if message.MessageId != "" {
+		attrs = append(attrs, semconv.MessagingMessageID(message.MessageId))
+	}
+	ctx, span := otel.Tracer(TracerName).Start(ctx, "publish "+_destinationName(exchange),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attrs...),
+	)
+
+	headers := maps.Clone(message.Headers)
+	if headers == nil {
+		headers = amqp091.Table{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+
+	// Without a tracer provider the span is not valid, keep the legacy trace ID
+	traceID := reqctx.TraceIDFrom(ctx)
+	if span.SpanContext().IsValid() {
+		traceID = span.SpanContext().TraceID().String()
+	}
+	if _, ok := headers[TraceIDHeaderKey]; !ok && traceID != "" {
+		headers[TraceIDHeaderKey] = traceID
+	}
+	message.Headers = headers
+	return ctx, span, message
+}
+
+// _startConsumeSpan extracts the trace context and baggage of the message and starts the
+// consumer span, as a child of the producer span and linked to it.
+func _startConsumeSpan(ctx context.Context, queue string, message amqp091.Delivery) (context.Context, trace.Span) {
+	if message.Headers != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(message.Headers))
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingOperationTypeDeliver,
+		semconv.MessagingOperationName("process"),
+		semconv.MessagingDestinationName(queue),
+		semconv.MessagingMessageBodySize(len(message.Body)),
+		semconv.MessagingRabbitmqMessageDeliveryTag(int(message.DeliveryTag)),
+	}
+	if message.RoutingKey != "" {
+		attrs = append(attrs, semconv.MessagingRabbitmqDestinationRoutingKey(message.RoutingKey))
+	}
+	if message.MessageId != "" {
+		attrs = append(attrs, semconv.MessagingMessageID(message.MessageId))
+	}
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs...),
+	}
+	if producer := trace.SpanContextFromContext(ctx); producer.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: producer}))
+	}
+	return otel.Tracer(TracerName).Start(ctx, "process "+queue, opts...)
+}
+
+func _endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
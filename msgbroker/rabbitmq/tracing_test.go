@@ -0,0 +1,64 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	defer provider.Shutdown(context.Background())
+
+	member, _ := baggage.NewMember("tenant", "acme")
+	bag, _ := baggage.New(member)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	original := amqp091.Publishing{Headers: amqp091.Table{"source": "api"}, Body: []byte("{}")}
+	_, publishSpan, message := _startPublishSpan(ctx, "", "orders.created", original)
+	publishSpan.End()
+	if len(original.Headers) != 1 {
+		t.Errorf("the original message headers were modified")
+	}
+
+	delivery := amqp091.Delivery{Headers: message.Headers, Body: message.Body, RoutingKey: "orders.created", DeliveryTag: 1}
+	consumeCtx, consumeSpan := _startConsumeSpan(context.Background(), "billing", delivery)
+	consumeSpan.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	producer, consumer := spans[0], spans[1]
+	if producer.Name() != "publish amq.default" || consumer.Name() != "process billing" {
+		t.Errorf("unexpected span names %q and %q", producer.Name(), consumer.Name())
+	}
+	if producer.SpanKind() != trace.SpanKindProducer || consumer.SpanKind() != trace.SpanKindConsumer {
+		t.Errorf("unexpected span kinds %v and %v", producer.SpanKind(), consumer.SpanKind())
+	}
+	if consumer.Parent().SpanID() != producer.SpanContext().SpanID() {
+		t.Errorf("the consumer span is not a child of the producer span")
+	}
+	if links := consumer.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != producer.SpanContext().SpanID() {
+		t.Errorf("the consumer span is not linked to the producer span")
+	}
+	if got := baggage.FromContext(consumeCtx).Member("tenant").Value(); got != "acme" {
+		t.Errorf("baggage was not propagated, got %q", got)
+	}
+	if got := headerCarrier(message.Headers).Get(TraceIDHeaderKey); got != producer.SpanContext().TraceID().String() {
+		t.Errorf("unexpected legacy trace ID header %q", got)
+	}
+	if message.Headers["source"] != "api" {
+		t.Errorf("the existing headers were not kept, got %v", message.Headers)
+	}
+}
@@ -0,0 +1,288 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Mode string
+
+const (
+	OffsetMode Mode = "offset"
+	CursorMode Mode = "cursor"
+)
+
+type Operator string
+
+const (
+	Eq   Operator = "eq"
+	Ne   Operator = "ne"
+	Gt   Operator = "gt"
+	Gte  Operator = "gte"
+	Lt   Operator = "lt"
+	Lte  Operator = "lte"
+	Like Operator = "like"
+	In   Operator = "in"
+)
+
+func (o Operator) valid() bool {
+	switch o {
+	case Eq, Ne, Gt, Gte, Lt, Lte, Like, In:
+		return true
+	default:
+		return false
+	}
+}
+
+type Sort struct {
+	// Column is the whitelisted column name, never the raw query value.
+	Column string
+	Desc   bool
+}
+
+type Filter struct {
+	// Column is the whitelisted column name, never the raw query value.
+	Column   string
+	Operator Operator
+	Value    string
+	// Values holds the comma separated values of the In operator.
+	Values []string
+}
+
+type Request struct {
+	Mode    Mode
+	Page    int
+	Limit   int
+	Cursor  *Cursor
+	Sorts   []Sort
+	Filters []Filter
+}
+
+// Offset returns the number of rows to skip in offset mode.
+func (r *Request) Offset() int {
+	return (r.Page - 1) * r.Limit
+}
+
+type Config struct {
+	// DefaultLimit is the page size used when the limit is not given.
+	// Default is 20.
+	DefaultLimit int
+
+	// MaxLimit is the maximum page size a client can request.
+	// Default is 100.
+	MaxLimit int
+
+	// SortFields maps the field names accepted in the `sort` parameter to column names.
+	// Sorting on any other field is rejected.
+	SortFields map[string]string
+
+	// FilterFields maps the field names accepted in `filter[...]` parameters to column names.
+	// Filtering on any other field is rejected.
+	FilterFields map[string]string
+
+	// DefaultSort is applied when the `sort` parameter is not given, e.g. "-created_at".
+	DefaultSort string
+
+	// CursorColumn is the unique, sortable column used by the cursor mode.
+	// Default is "id".
+	CursorColumn string
+}
+
+func NewConfig(config *Config) *Config {
+	if config == nil {
+		config = &Config{}
+	}
+	if config.DefaultLimit == 0 {
+		config.DefaultLimit = 20
+	}
+	if config.MaxLimit == 0 {
+		config.MaxLimit = 100
+	}
+	if config.CursorColumn == "" {
+		config.CursorColumn = "id"
+	}
+	return config
+}
+
+var filterKeyRegex = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([a-z]+)\])?$`)
+
+/*
+Parse builds a Request from query string parameters:
+  - page, limit: offset mode, page starts at 1.
+  - cursor, limit: cursor mode, the cursor is the opaque value returned in Meta.
+  - sort: comma separated fields, prefixed with "-" for descending order, e.g. "-created_at,name".
+  - filter[<field>]=<value> or filter[<field>][<operator>]=<value>, with the operators
+    eq, ne, gt, gte, lt, lte, like and in (comma separated values).
+
+The returned errors are keyed by parameter, ready for exception.InvalidParameter.
+*/
+func Parse(query map[string]string, config *Config) (*Request, map[string]string) {
+	config = NewConfig(config)
+	errs := make(map[string]string)
+	req := &Request{Mode: OffsetMode, Page: 1, Limit: config.DefaultLimit}
+
+	if limit, ok := query["limit"]; ok && limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			errs["limit"] = "limit must be a positive number"
+		} else {
+			req.Limit = min(n, config.MaxLimit)
+		}
+	}
+
+	if cursor, ok := query["cursor"]; ok {
+		req.Mode = CursorMode
+		if cursor != "" {
+			c, err := DecodeCursor(cursor)
+			if err != nil {
+				errs["cursor"] = "cursor is not valid"
+			}
+			req.Cursor = c
+		}
+	} else if page, ok := query["page"]; ok && page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			errs["page"] = "page must be a positive number"
+		} else {
+			req.Page = n
+		}
+	}
+
+	sort := query["sort"]
+	// The default sort is trusted configuration, only client values are whitelisted
+	trusted := sort == ""
+	if trusted {
+		sort = config.DefaultSort
+	}
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		name, desc := strings.CutPrefix(field, "-")
+		name = strings.TrimPrefix(name, "+")
+		column, ok := config.SortFields[name]
+		if !ok && req.Mode == CursorMode && name == config.CursorColumn {
+			column, ok = name, true
+		}
+		if !ok {
+			if !trusted {
+				errs["sort"] = fmt.Sprintf("sorting by %s is not allowed", name)
+				continue
+			}
+			column = name
+		}
+		req.Sorts = append(req.Sorts, Sort{Column: column, Desc: desc})
+	}
+	if req.Mode == CursorMode {
+		// Keyset pagination requires a unique sort key, so only the direction can be chosen
+		desc := false
+		for _, s := range req.Sorts {
+			if s.Column != config.CursorColumn && !trusted {
+				errs["sort"] = "cursor pagination only supports sorting by " + config.CursorColumn
+			}
+			desc = s.Desc
+		}
+		req.Sorts = []Sort{{Column: config.CursorColumn, Desc: desc}}
+	}
+
+	for key, value := range query {
+		matches := filterKeyRegex.FindStringSubmatch(key)
+		if matches == nil {
+			continue
+		}
+		column, ok := config.FilterFields[matches[1]]
+		if !ok {
+			errs[key] = fmt.Sprintf("filtering by %s is not allowed", matches[1])
+			continue
+		}
+		operator := Eq
+		if matches[2] != "" {
+			operator = Operator(matches[2])
+		}
+		if !operator.valid() {
+			errs[key] = fmt.Sprintf("operator %s is not supported", operator)
+			continue
+		}
+		filter := Filter{Column: column, Operator: operator, Value: value}
+		if operator == In {
+			filter.Values = strings.Split(value, ",")
+		}
+		req.Filters = append(req.Filters, filter)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return req, nil
+}
+
+// Cursor points at a row of a cursor paginated list.
+type Cursor struct {
+	// Value is the value of the cursor column of the row.
+	Value any `json:"v"`
+	// Prev is true when the cursor points backwards, to the page before the row.
+	Prev bool `json:"p,omitempty"`
+}
+
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	// Keep numbers as json.Number so large IDs do not lose precision
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var c Cursor
+	if err := decoder.Decode(&c); err != nil {
+		return nil, err
+	}
+	switch c.Value.(type) {
+	case nil:
+		return nil, errors.New("cursor value is missing")
+	case string, json.Number, bool:
+	default:
+		// Objects and arrays cannot be compared with a column
+		return nil, errors.New("cursor value must be a scalar")
+	}
+	return &c, nil
+}
+
+// Meta describes the page returned to the client.
+type Meta struct {
+	Page       int    `json:"page,omitempty" xml:"page,omitempty"`
+	Limit      int    `json:"limit" xml:"limit"`
+	Total      int64  `json:"total,omitempty" xml:"total,omitempty"`
+	TotalPages int    `json:"total_pages,omitempty" xml:"total_pages,omitempty"`
+	HasNext    bool   `json:"has_next" xml:"has_next"`
+	HasPrev    bool   `json:"has_prev" xml:"has_prev"`
+	NextCursor string `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty" xml:"prev_cursor,omitempty"`
+}
+
+// NewOffsetMeta builds the Meta of an offset paginated page.
+func NewOffsetMeta(req *Request, total int64) *Meta {
+	totalPages := 0
+	if req.Limit > 0 {
+		totalPages = int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	}
+	return &Meta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    req.Page < totalPages,
+		HasPrev:    req.Page > 1,
+	}
+}
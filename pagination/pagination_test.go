@@ -0,0 +1,89 @@
+package pagination
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var testConfig = &Config{
+	SortFields:   map[string]string{"name": "name", "createdAt": "created_at"},
+	FilterFields: map[string]string{"name": "name", "status": "status"},
+	DefaultSort:  "-created_at",
+}
+
+func TestParseOffset(t *testing.T) {
+	req, errs := Parse(map[string]string{
+		"page":                 "3",
+		"limit":                "500",
+		"sort":                 "name,-createdAt",
+		"filter[name][like]":   "jo",
+		"filter[status][in]":   "active,pending",
+		"unrelated[parameter]": "ignored",
+	}, testConfig)
+	if errs != nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if req.Mode != OffsetMode || req.Page != 3 || req.Limit != 100 || req.Offset() != 200 {
+		t.Errorf("unexpected page: %+v", req)
+	}
+	if len(req.Sorts) != 2 || req.Sorts[0] != (Sort{Column: "name"}) || req.Sorts[1] != (Sort{Column: "created_at", Desc: true}) {
+		t.Errorf("unexpected sorts: %+v", req.Sorts)
+	}
+	if len(req.Filters) != 2 {
+		t.Fatalf("unexpected filters: %+v", req.Filters)
+	}
+}
+
+func TestParseDefaultSort(t *testing.T) {
+	req, errs := Parse(map[string]string{}, testConfig)
+	if errs != nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(req.Sorts) != 1 || req.Sorts[0] != (Sort{Column: "created_at", Desc: true}) {
+		t.Errorf("unexpected sorts: %+v", req.Sorts)
+	}
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	_, errs := Parse(map[string]string{
+		"page":                   "0",
+		"sort":                   "password",
+		"filter[password]":       "x",
+		"filter[name][contains]": "x",
+	}, testConfig)
+	for _, key := range []string{"page", "sort", "filter[password]", "filter[name][contains]"} {
+		if _, ok := errs[key]; !ok {
+			t.Errorf("expected an error for %s, got %v", key, errs)
+		}
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	cursor := (&Cursor{Value: int64(1234567890123456789), Prev: true}).Encode()
+	req, errs := Parse(map[string]string{"cursor": cursor, "sort": "-id"}, testConfig)
+	if errs != nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if req.Mode != CursorMode || !req.Cursor.Prev {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if req.Cursor.Value != json.Number("1234567890123456789") {
+		t.Errorf("cursor value lost precision: %v", req.Cursor.Value)
+	}
+	if len(req.Sorts) != 1 || req.Sorts[0] != (Sort{Column: "id", Desc: true}) {
+		t.Errorf("unexpected sorts: %+v", req.Sorts)
+	}
+
+	if _, errs := Parse(map[string]string{"cursor": cursor, "sort": "name"}, testConfig); errs["sort"] == "" {
+		t.Errorf("expected sorting by another column to be rejected in cursor mode")
+	}
+	if _, errs := Parse(map[string]string{"cursor": "not a cursor"}, testConfig); errs["cursor"] == "" {
+		t.Errorf("expected an invalid cursor to be rejected")
+	}
+	for _, value := range []any{map[string]any{"id": 1}, []any{1, 2}} {
+		cursor := (&Cursor{Value: value}).Encode()
+		if _, errs := Parse(map[string]string{"cursor": cursor}, testConfig); errs["cursor"] == "" {
+			t.Errorf("expected the cursor value %v to be rejected", value)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+// Maximum argon2id parameters accepted by Compare, so a crafted hash cannot make
+// the verification allocate or compute arbitrarily much.
+const (
+	maxArgon2Memory      = 1024 * 1024 // 1 GiB
+	maxArgon2Iterations  = 64
+	maxArgon2Parallelism = 64
+)
+
+type Argon2idConfig struct {
+	// Memory is the memory cost in KiB, at most 1 GiB.
+	// Default is 64 MiB.
+	Memory uint32
+
+	// Iterations is the number of passes over the memory, at most 64.
+	// Default is 3.
+	Iterations uint32
+
+	// Parallelism is the number of threads, at most 64.
+	// Default is 2.
+	Parallelism uint8
+
+	// SaltLength is the length of the random salt in bytes.
+	// Default is 16.
+	SaltLength uint32
+
+	// KeyLength is the length of the generated hash in bytes.
+	// Default is 32.
+	KeyLength uint32
+}
+
+// Argon2idHasher hashes passwords with argon2id into PHC strings:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+type Argon2idHasher struct {
+	config *Argon2idConfig
+}
+
+// NewArgon2idHasher creates an Argon2idHasher, rejecting parameters above the maxima
+// accepted by Compare, as their hashes could never be verified.
+func NewArgon2idHasher(config *Argon2idConfig) (*Argon2idHasher, error) {
+	if config == nil {
+		config = &Argon2idConfig{}
+	}
+	if config.Memory == 0 {
+		config.Memory = 64 * 1024
+	}
+	if config.Iterations == 0 {
+		config.Iterations = 3
+	}
+	if config.Parallelism == 0 {
+		config.Parallelism = 2
+	}
+	if config.SaltLength == 0 {
+		config.SaltLength = 16
+	}
+	if config.KeyLength == 0 {
+		config.KeyLength = 32
+	}
+	if _argon2ParamsExceed(int(config.Memory), int(config.Iterations), int(config.Parallelism)) {
+		return nil, fmt.Errorf("password: argon2id parameters exceed the maximum of %d KiB, %d iterations and %d threads",
+			maxArgon2Memory, maxArgon2Iterations, maxArgon2Parallelism)
+	}
+	return &Argon2idHasher{config: config}, nil
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.config.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	hash := argon2.IDKey([]byte(password), salt, h.config.Iterations, h.config.Memory, h.config.Parallelism, h.config.KeyLength)
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", h.config.Memory, h.config.Iterations, h.config.Parallelism)
+	return _encodePHC("argon2id", argon2.Version, params, salt, hash), nil
+}
+
+func (h *Argon2idHasher) Compare(encoded, password string) error {
+	p, err := _parsePHC(encoded)
+	if err != nil {
+		return err
+	}
+	if p.id != "argon2id" {
+		return ErrUnsupportedHash
+	}
+	if p.version != argon2.Version {
+		return fmt.Errorf("%w: argon2 version %d", ErrUnsupportedHash, p.version)
+	}
+	m, t, par := p.params["m"], p.params["t"], p.params["p"]
+	if m <= 0 || t <= 0 || par <= 0 || len(p.hash) == 0 {
+		return ErrInvalidHash
+	}
+	if _argon2ParamsExceed(m, t, par) {
+		return fmt.Errorf("%w: argon2id parameters exceed the maximum", ErrInvalidHash)
+	}
+
+	hash := argon2.IDKey([]byte(password), p.salt, uint32(t), uint32(m), uint8(par), uint32(len(p.hash)))
+	if subtle.ConstantTimeCompare(hash, p.hash) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+	return nil
+}
+
+func _argon2ParamsExceed(memory, iterations, parallelism int) bool {
+	return memory > maxArgon2Memory || iterations > maxArgon2Iterations || parallelism > maxArgon2Parallelism
+}
+
+func (h *Argon2idHasher) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	p, err := _parsePHC(encoded)
+	if err != nil || p.id != "argon2id" {
+		return true
+	}
+	return p.version != argon2.Version ||
+		p.params["m"] != int(h.config.Memory) ||
+		p.params["t"] != int(h.config.Iterations) ||
+		p.params["p"] != int(h.config.Parallelism) ||
+		len(p.salt) != int(h.config.SaltLength) ||
+		len(p.hash) != int(h.config.KeyLength)
+}
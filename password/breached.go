@@ -0,0 +1,163 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RangeSource returns the breached hashes sharing a 5 character SHA-1 prefix,
+// in the Have I Been Pwned range format: one "<35 char SUFFIX>:<COUNT>" per line.
+// Only the prefix leaves the checker, which keeps the lookup k-anonymous even
+// when the source is backed by a remote service.
+type RangeSource interface {
+	Range(prefix string) (io.ReadCloser, error)
+}
+
+// DirRangeSource reads ranges from a directory with one file per prefix,
+// named "<PREFIX>.txt" or "<PREFIX>", as produced by the HIBP downloader.
+type DirRangeSource struct {
+	Dir string
+}
+
+func (s *DirRangeSource) Range(prefix string) (io.ReadCloser, error) {
+	file, err := os.Open(filepath.Join(s.Dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		file, err = os.Open(filepath.Join(s.Dir, prefix))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	return file, err
+}
+
+// FileRangeSource reads ranges from a single file of "<40 char HASH>:<COUNT>" lines
+// sorted by hash, using a binary search so the file is never loaded in memory.
+type FileRangeSource struct {
+	Path string
+}
+
+func (s *FileRangeSource) Range(prefix string) (io.ReadCloser, error) {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the offset of the first line whose hash prefix is >= prefix
+	lo, hi := int64(0), info.Size()
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, line, err := _lineAt(file, mid)
+		if errors.Is(err, io.EOF) {
+			hi = mid
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _linePrefix(line) < prefix {
+			lo = start + int64(len(line))
+		} else {
+			hi = mid
+		}
+	}
+
+	start, _, err := _lineAt(file, lo)
+	if errors.Is(err, io.EOF) {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if _linePrefix(line) != prefix {
+			break
+		}
+		buf.WriteString(line[len(prefix):] + "\n")
+	}
+	return io.NopCloser(&buf), scanner.Err()
+}
+
+// _lineAt returns the first line starting at or after offset, including its newline.
+func _lineAt(file *os.File, offset int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		start = offset - 1
+	}
+	reader := bufio.NewReader(io.NewSectionReader(file, start, 1<<62))
+	if offset > 0 {
+		skipped, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, "", io.EOF
+		}
+		start += int64(len(skipped))
+	}
+	line, err := reader.ReadString('\n')
+	if line == "" && err != nil {
+		return 0, "", io.EOF
+	}
+	return start, line, nil
+}
+
+func _linePrefix(line string) string {
+	if len(line) < 5 {
+		return ""
+	}
+	return strings.ToUpper(line[:5])
+}
+
+// BreachedChecker checks passwords against a list of breached password hashes.
+type BreachedChecker struct {
+	source RangeSource
+}
+
+func NewBreachedChecker(source RangeSource) *BreachedChecker {
+	return &BreachedChecker{source: source}
+}
+
+// Count returns how many times the password appears in the breached list, or 0 when it is not found.
+func (b *BreachedChecker) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	reader, err := b.source.Range(prefix)
+	if err != nil {
+		return 0, fmt.Errorf("password: failed to read breached range %s: %w", prefix, err)
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, fmt.Errorf("password: invalid breached count for %s: %w", prefix, err)
+		}
+		return n, nil
+	}
+	return 0, scanner.Err()
+}
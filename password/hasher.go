@@ -0,0 +1,161 @@
+package password
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMismatchedHashAndPassword = errors.New("password: hashed password is not the hash of the given password")
+	ErrUnsupportedHash           = errors.New("password: unsupported hash algorithm")
+	ErrInvalidHash               = errors.New("password: invalid hash format")
+)
+
+// Hasher hashes passwords into self-describing strings.
+type Hasher interface {
+	// Hash returns the encoded hash of the password.
+	Hash(password string) (string, error)
+
+	// Compare returns nil when the hash matches the password,
+	// and ErrMismatchedHashAndPassword otherwise.
+	Compare(hash, password string) error
+
+	// Identifies reports whether the hash was produced by this algorithm.
+	Identifies(hash string) bool
+
+	// NeedsRehash reports whether the hash was produced with parameters
+	// different from the hasher's current parameters.
+	NeedsRehash(hash string) bool
+}
+
+// Manager hashes new passwords with its current hasher and verifies hashes
+// produced by any of its hashers, so users can be migrated to a stronger
+// algorithm or stronger parameters transparently at login:
+//
+//	needsRehash, err := manager.Verify(user.Password, input)
+//	if err != nil {
+//		return err
+//	}
+//	if needsRehash {
+//		user.Password, _ = manager.Hash(input)
+//	}
+type Manager struct {
+	current Hasher
+	hashers []Hasher
+}
+
+// NewManager creates a Manager hashing with current and also verifying hashes of the legacy hashers.
+func NewManager(current Hasher, legacy ...Hasher) *Manager {
+	return &Manager{
+		current: current,
+		hashers: append([]Hasher{current}, legacy...),
+	}
+}
+
+// DefaultManager hashes with argon2id and verifies argon2id, scrypt and bcrypt hashes.
+var DefaultManager = NewManager(_mustHasher(NewArgon2idHasher(nil)), _mustHasher(NewScryptHasher(nil)), NewBcryptHasher(0))
+
+// _mustHasher panics when a hasher with the default parameters cannot be created.
+func _mustHasher[T Hasher](hasher T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return hasher
+}
+
+func (m *Manager) Hash(password string) (string, error) {
+	return m.current.Hash(password)
+}
+
+// Verify detects the algorithm from the hash and compares it with the password.
+// needsRehash is true when the password is correct but the hash was not produced
+// by the current hasher with its current parameters.
+func (m *Manager) Verify(hash, password string) (needsRehash bool, err error) {
+	for _, hasher := range m.hashers {
+		if !hasher.Identifies(hash) {
+			continue
+		}
+		if err := hasher.Compare(hash, password); err != nil {
+			return false, err
+		}
+		return hasher != m.current || hasher.NeedsRehash(hash), nil
+	}
+	return false, ErrUnsupportedHash
+}
+
+// Hash hashes the password with DefaultManager.
+func Hash(password string) (string, error) {
+	return DefaultManager.Hash(password)
+}
+
+// Verify verifies the password with DefaultManager.
+func Verify(hash, password string) (needsRehash bool, err error) {
+	return DefaultManager.Verify(hash, password)
+}
+
+// phc is a hash in the PHC string format:
+// $<id>[$v=<version>]$<param>=<value>(,<param>=<value>)*$<salt>$<hash>
+type phc struct {
+	id      string
+	version int
+	params  map[string]int
+	salt    []byte
+	hash    []byte
+}
+
+func _encodePHC(id string, version int, params string, salt, hash []byte) string {
+	var b strings.Builder
+	b.WriteString("$" + id)
+	if version != 0 {
+		fmt.Fprintf(&b, "$v=%d", version)
+	}
+	b.WriteString("$" + params)
+	b.WriteString("$" + base64.RawStdEncoding.EncodeToString(salt))
+	b.WriteString("$" + base64.RawStdEncoding.EncodeToString(hash))
+	return b.String()
+}
+
+func _parsePHC(encoded string) (*phc, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) < 5 || parts[0] != "" {
+		return nil, ErrInvalidHash
+	}
+	p := &phc{id: parts[1], params: make(map[string]int)}
+	parts = parts[2:]
+
+	if version, ok := strings.CutPrefix(parts[0], "v="); ok {
+		v, err := strconv.Atoi(version)
+		if err != nil {
+			return nil, ErrInvalidHash
+		}
+		p.version = v
+		parts = parts[1:]
+	}
+	if len(parts) != 3 {
+		return nil, ErrInvalidHash
+	}
+
+	for _, param := range strings.Split(parts[0], ",") {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, ErrInvalidHash
+		}
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, ErrInvalidHash
+		}
+		p.params[key] = v
+	}
+
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[1]); err != nil {
+		return nil, ErrInvalidHash
+	}
+	if p.hash, err = base64.RawStdEncoding.DecodeString(parts[2]); err != nil {
+		return nil, ErrInvalidHash
+	}
+	return p, nil
+}
@@ -0,0 +1,137 @@
+package password
+
+import (
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+)
+
+func TestHashers(t *testing.T) {
+	hashers := map[string]Hasher{
+		"argon2id": _mustHasher(NewArgon2idHasher(&Argon2idConfig{Memory: 1024, Iterations: 1})),
+		"scrypt":   _mustHasher(NewScryptHasher(&ScryptConfig{LogN: 10})),
+		"bcrypt":   NewBcryptHasher(bcrypt.MinCost),
+	}
+	for name, hasher := range hashers {
+		password := "mySecret123!"
+		hash, err := hasher.Hash(password)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if !hasher.Identifies(hash) {
+			t.Errorf("%s: expected hasher to identify its own hash %s", name, hash)
+		}
+		if err := hasher.Compare(hash, password); err != nil {
+			t.Errorf("%s: expected passwords to match, got error: %v", name, err)
+		}
+		if err := hasher.Compare(hash, "wrongPassword"); !errors.Is(err, ErrMismatchedHashAndPassword) {
+			t.Errorf("%s: expected ErrMismatchedHashAndPassword, got %v", name, err)
+		}
+		if hasher.NeedsRehash(hash) {
+			t.Errorf("%s: expected fresh hash not to need rehash", name)
+		}
+	}
+}
+
+func TestPHCFormat(t *testing.T) {
+	hash, err := _mustHasher(NewArgon2idHasher(&Argon2idConfig{Memory: 1024, Iterations: 1})).Hash("mySecret123!")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=2$") {
+		t.Errorf("unexpected argon2id hash format: %s", hash)
+	}
+
+	hash, err = _mustHasher(NewScryptHasher(&ScryptConfig{LogN: 10})).Hash("mySecret123!")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(hash, "$scrypt$ln=10,r=8,p=1$") {
+		t.Errorf("unexpected scrypt hash format: %s", hash)
+	}
+}
+
+func TestManagerRehash(t *testing.T) {
+	password := "mySecret123!"
+	legacy, err := GeneratePassword(password)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	weak := _mustHasher(NewArgon2idHasher(&Argon2idConfig{Memory: 1024, Iterations: 1}))
+	strong := _mustHasher(NewArgon2idHasher(&Argon2idConfig{Memory: 2048, Iterations: 2}))
+	manager := NewManager(strong, NewBcryptHasher(0))
+
+	// Legacy bcrypt hashes verify but must be migrated
+	needsRehash, err := manager.Verify(legacy, password)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !needsRehash {
+		t.Errorf("expected bcrypt hash to need rehash")
+	}
+
+	// Hashes with weaker parameters must be migrated
+	weakHash, _ := weak.Hash(password)
+	if needsRehash, err := manager.Verify(weakHash, password); err != nil || !needsRehash {
+		t.Errorf("expected weak hash to need rehash, got %v, %v", needsRehash, err)
+	}
+
+	// Hashes from the current hasher are kept
+	hash, _ := manager.Hash(password)
+	if needsRehash, err := manager.Verify(hash, password); err != nil || needsRehash {
+		t.Errorf("expected current hash not to need rehash, got %v, %v", needsRehash, err)
+	}
+
+	if _, err := manager.Verify(hash, "wrongPassword"); !errors.Is(err, ErrMismatchedHashAndPassword) {
+		t.Errorf("expected ErrMismatchedHashAndPassword, got %v", err)
+	}
+	if _, err := manager.Verify("plaintext", password); !errors.Is(err, ErrUnsupportedHash) {
+		t.Errorf("expected ErrUnsupportedHash, got %v", err)
+	}
+}
+
+func TestBcryptRejectsLongPasswords(t *testing.T) {
+	if _, err := NewBcryptHasher(bcrypt.MinCost).Hash(strings.Repeat("a", 73)); err == nil {
+		t.Errorf("expected error for password longer than 72 bytes, got nil")
+	}
+}
+
+func TestHashersRejectExcessiveParameters(t *testing.T) {
+	hash := "$c2FsdHNhbHRzYWx0c2FsdA$aGFzaGhhc2hoYXNoaGFzaGhhc2hoYXNoaGFzaGhhc2g"
+	tests := []struct {
+		hasher  Hasher
+		encoded string
+	}{
+		{_mustHasher(NewArgon2idHasher(nil)), "$argon2id$v=19$m=4194304,t=3,p=2" + hash},
+		{_mustHasher(NewArgon2idHasher(nil)), "$argon2id$v=19$m=65536,t=100000,p=2" + hash},
+		{_mustHasher(NewArgon2idHasher(nil)), "$argon2id$v=19$m=65536,t=3,p=255" + hash},
+		{_mustHasher(NewScryptHasher(nil)), "$scrypt$ln=30,r=8,p=1" + hash},
+		{_mustHasher(NewScryptHasher(nil)), "$scrypt$ln=15,r=1024,p=1" + hash},
+		{_mustHasher(NewScryptHasher(nil)), "$scrypt$ln=15,r=8,p=1000" + hash},
+	}
+	for _, tt := range tests {
+		if err := tt.hasher.Compare(tt.encoded, "mySecret123!"); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("%s: expected ErrInvalidHash, got %v", tt.encoded, err)
+		}
+	}
+}
+
+func TestHashersRejectExcessiveConfig(t *testing.T) {
+	if _, err := NewArgon2idHasher(&Argon2idConfig{Memory: 2 * 1024 * 1024}); err == nil {
+		t.Errorf("expected an error for argon2id memory above the maximum")
+	}
+	if _, err := NewArgon2idHasher(&Argon2idConfig{Iterations: 100}); err == nil {
+		t.Errorf("expected an error for argon2id iterations above the maximum")
+	}
+	if _, err := NewScryptHasher(&ScryptConfig{LogN: 21}); err == nil {
+		t.Errorf("expected an error for scrypt N above the maximum")
+	}
+	if _, err := NewScryptHasher(&ScryptConfig{P: 32}); err == nil {
+		t.Errorf("expected an error for scrypt p above the maximum")
+	}
+	if _, err := NewScryptHasher(&ScryptConfig{LogN: 20}); err != nil {
+		t.Errorf("expected 1 GiB to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package password
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	ViolationMinLength     = "MIN_LENGTH"
+	ViolationMaxLength     = "MAX_LENGTH"
+	ViolationUpper         = "UPPERCASE"
+	ViolationLower         = "LOWERCASE"
+	ViolationDigit         = "DIGIT"
+	ViolationSymbol        = "SYMBOL"
+	ViolationRepeated      = "REPEATED_CHARACTERS"
+	ViolationUserAttribute = "USER_ATTRIBUTE"
+	ViolationEntropy       = "LOW_ENTROPY"
+	ViolationBreached      = "BREACHED"
+)
+
+type Violation struct {
+	Code    string
+	Message string
+}
+
+type Violations []Violation
+
+// ErrorMap converts the violations into the map expected by exception.InvalidParameter,
+// with all messages joined under the given field name. It returns nil when there are no violations.
+//
+//	if violations := policy.Validate(req.Password, req.Email); len(violations) > 0 {
+//		return exception.InvalidParameter("Invalid password", violations.ErrorMap("password"))
+//	}
+func (v Violations) ErrorMap(field string) map[string]string {
+	if len(v) == 0 {
+		return nil
+	}
+	messages := make([]string, len(v))
+	for i, violation := range v {
+		messages[i] = violation.Message
+	}
+	return map[string]string{field: strings.Join(messages, "; ")}
+}
+
+type Policy struct {
+	// MinLength is the minimum number of characters.
+	// Default is 8.
+	MinLength int
+
+	// MaxLength is the maximum number of characters. Zero means no limit.
+	MaxLength int
+
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+
+	// MaxRepeated is the maximum number of consecutive identical characters. Zero means no limit.
+	MaxRepeated int
+
+	// MinEntropy is the minimum estimated entropy in bits, see Entropy. Zero means no limit.
+	MinEntropy float64
+
+	// MaxSimilarity is the maximum similarity, between 0 and 1, to the user attributes
+	// passed to Validate. Zero uses the default, even when the Policy was not created by
+	// NewPolicy, and 1 disables the check. Default is 0.7.
+	MaxSimilarity float64
+
+	// Breached is an optional checker rejecting passwords found in known breaches.
+	Breached *BreachedChecker
+}
+
+const defaultMaxSimilarity = 0.7
+
+func NewPolicy(policy *Policy) *Policy {
+	if policy == nil {
+		policy = &Policy{}
+	}
+	if policy.MinLength == 0 {
+		policy.MinLength = 8
+	}
+	if policy.MaxSimilarity == 0 {
+		policy.MaxSimilarity = defaultMaxSimilarity
+	}
+	return policy
+}
+
+// Validate evaluates the password against the policy.
+// userAttributes are values such as the username or email the password must not resemble.
+func (p *Policy) Validate(password string, userAttributes ...string) Violations {
+	var violations Violations
+	add := func(code, format string, args ...any) {
+		violations = append(violations, Violation{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		add(ViolationMinLength, "password must be at least %d characters long", p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(ViolationMaxLength, "password must be at most %d characters long", p.MaxLength)
+	}
+
+	classes := _characterClasses(password)
+	if p.RequireUpper && !classes.upper {
+		add(ViolationUpper, "password must contain an uppercase letter")
+	}
+	if p.RequireLower && !classes.lower {
+		add(ViolationLower, "password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !classes.digit {
+		add(ViolationDigit, "password must contain a digit")
+	}
+	if p.RequireSymbol && !classes.symbol {
+		add(ViolationSymbol, "password must contain a symbol")
+	}
+
+	if p.MaxRepeated > 0 && _maxRepeated(password) > p.MaxRepeated {
+		add(ViolationRepeated, "password must not contain more than %d identical characters in a row", p.MaxRepeated)
+	}
+
+	maxSimilarity := p.MaxSimilarity
+	if maxSimilarity == 0 {
+		maxSimilarity = defaultMaxSimilarity
+	}
+	for _, attribute := range userAttributes {
+		if _similarity(password, attribute) > maxSimilarity {
+			add(ViolationUserAttribute, "password is too similar to your personal information")
+			break
+		}
+	}
+
+	if p.MinEntropy > 0 && Entropy(password) < p.MinEntropy {
+		add(ViolationEntropy, "password is too easy to guess")
+	}
+
+	if p.Breached != nil {
+		count, err := p.Breached.Count(password)
+		if err != nil {
+			slog.Warn("Password: Failed to check breached passwords", "error", err.Error())
+		} else if count > 0 {
+			add(ViolationBreached, "password has appeared in a data breach")
+		}
+	}
+
+	return violations
+}
+
+// Entropy estimates the entropy of the password in bits from its length and the size
+// of the character classes it uses. Repeated characters do not add entropy.
+func Entropy(password string) float64 {
+	classes := _characterClasses(password)
+	pool := 0
+	if classes.lower {
+		pool += 26
+	}
+	if classes.upper {
+		pool += 26
+	}
+	if classes.digit {
+		pool += 10
+	}
+	if classes.symbol {
+		pool += 33
+	}
+	if classes.other {
+		pool += 100
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	length := 0
+	var prev rune
+	for i, r := range password {
+		if i == 0 || r != prev {
+			length++
+		}
+		prev = r
+	}
+	return float64(length) * math.Log2(float64(pool))
+}
+
+type characterClasses struct {
+	upper, lower, digit, symbol, other bool
+}
+
+func _characterClasses(password string) characterClasses {
+	var c characterClasses
+	for _, r := range password {
+		switch {
+		case r <= unicode.MaxASCII && unicode.IsUpper(r):
+			c.upper = true
+		case r <= unicode.MaxASCII && unicode.IsLower(r):
+			c.lower = true
+		case r <= unicode.MaxASCII && unicode.IsDigit(r):
+			c.digit = true
+		case r <= unicode.MaxASCII && (unicode.IsPunct(r) || unicode.IsSymbol(r) || r == ' '):
+			c.symbol = true
+		default:
+			c.other = true
+		}
+	}
+	return c
+}
+
+func _maxRepeated(password string) int {
+	longest, current := 0, 0
+	var prev rune
+	for i, r := range password {
+		if i > 0 && r == prev {
+			current++
+		} else {
+			current = 1
+		}
+		longest = max(longest, current)
+		prev = r
+	}
+	return longest
+}
+
+// _similarity returns a value between 0 and 1 describing how close the password is
+// to the attribute, based on containment and on the Levenshtein distance.
+func _similarity(password, attribute string) float64 {
+	password = strings.ToLower(password)
+	attribute = strings.ToLower(attribute)
+	// Compare the local part of email addresses
+	if local, _, ok := strings.Cut(attribute, "@"); ok {
+		attribute = local
+	}
+	if utf8.RuneCountInString(attribute) < 3 || password == "" {
+		return 0
+	}
+	if strings.Contains(password, attribute) || strings.Contains(attribute, password) {
+		return 1
+	}
+
+	a, b := []rune(password), []rune(attribute)
+	longest := max(len(a), len(b))
+	return 1 - float64(_levenshtein(a, b))/float64(longest)
+}
+
+func _levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
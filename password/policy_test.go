@@ -0,0 +1,154 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func _violationCodes(violations Violations) []string {
+	codes := make([]string, len(violations))
+	for i, v := range violations {
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+func TestPolicyValidate(t *testing.T) {
+	policy := NewPolicy(&Policy{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		MaxRepeated:   2,
+		MinEntropy:    50,
+	})
+
+	if violations := policy.Validate("Correct-Horse-42-Battery"); len(violations) != 0 {
+		t.Errorf("expected no violations, got %v", violations)
+	}
+
+	tests := map[string]string{
+		"Ab1!":         ViolationMinLength,
+		"ABCDEFGH12!":  ViolationLower,
+		"abcdefgh12!":  ViolationUpper,
+		"Abcdefghij!":  ViolationDigit,
+		"Abcdefghij1":  ViolationSymbol,
+		"Abcdeffff1!x": ViolationRepeated,
+	}
+	for password, code := range tests {
+		codes := _violationCodes(policy.Validate(password))
+		found := false
+		for _, c := range codes {
+			found = found || c == code
+		}
+		if !found {
+			t.Errorf("%s: expected violation %s, got %v", password, code, codes)
+		}
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	if Entropy("") != 0 {
+		t.Errorf("expected empty password to have no entropy")
+	}
+	if Entropy("aaaaaaaaaa") >= Entropy("abcdefghij") {
+		t.Errorf("expected repeated characters not to add entropy")
+	}
+	if Entropy("abcdefghij") >= Entropy("aB3$efGh1!") {
+		t.Errorf("expected more character classes to add entropy")
+	}
+}
+
+func TestPolicyUserAttributes(t *testing.T) {
+	policy := NewPolicy(nil)
+	codes := _violationCodes(policy.Validate("johndoe2024", "johndoe@example.com"))
+	if len(codes) != 1 || codes[0] != ViolationUserAttribute {
+		t.Errorf("expected USER_ATTRIBUTE violation, got %v", codes)
+	}
+	if violations := policy.Validate("purple-monkey-dishwasher", "johndoe@example.com"); len(violations) != 0 {
+		t.Errorf("expected no violations, got %v", violations)
+	}
+
+	// A zero-value Policy uses the default threshold
+	if violations := (&Policy{}).Validate("purple-monkey-dishwasher", "johndoe@example.com"); len(violations) != 0 {
+		t.Errorf("expected no violations for a zero-value policy, got %v", violations)
+	}
+	if codes := _violationCodes((&Policy{}).Validate("johndoe2024", "johndoe@example.com")); len(codes) != 1 || codes[0] != ViolationUserAttribute {
+		t.Errorf("expected USER_ATTRIBUTE violation for a zero-value policy, got %v", codes)
+	}
+}
+
+func TestViolationsErrorMap(t *testing.T) {
+	policy := NewPolicy(&Policy{MinLength: 10, RequireDigit: true})
+	errorMap := policy.Validate("short").ErrorMap("password")
+	if !strings.Contains(errorMap["password"], "at least 10") || !strings.Contains(errorMap["password"], "digit") {
+		t.Errorf("unexpected error map: %v", errorMap)
+	}
+	if Violations(nil).ErrorMap("password") != nil {
+		t.Errorf("expected nil error map without violations")
+	}
+}
+
+func _sha1(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestBreachedChecker(t *testing.T) {
+	breached := map[string]int{"password": 9545824, "123456": 37359195, "qwerty": 3912816}
+	dir := t.TempDir()
+
+	// Single sorted file of full hashes, with unrelated filler lines around the entries
+	var lines []string
+	for password, count := range breached {
+		lines = append(lines, _sha1(password)+":"+strconv.Itoa(count))
+	}
+	for i := range 200 {
+		lines = append(lines, _sha1("filler-"+strconv.Itoa(i))+":1")
+	}
+	sort.Strings(lines)
+	file := filepath.Join(dir, "pwned.txt")
+	if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write breached file: %v", err)
+	}
+
+	// One file per prefix
+	rangeDir := filepath.Join(dir, "ranges")
+	_ = os.Mkdir(rangeDir, 0o700)
+	for password, count := range breached {
+		hash := _sha1(password)
+		_ = os.WriteFile(filepath.Join(rangeDir, hash[:5]+".txt"), []byte(hash[5:]+":"+strconv.Itoa(count)+"\r\n"), 0o600)
+	}
+
+	for name, source := range map[string]RangeSource{
+		"file": &FileRangeSource{Path: file},
+		"dir":  &DirRangeSource{Dir: rangeDir},
+	} {
+		checker := NewBreachedChecker(source)
+		for password, expected := range breached {
+			count, err := checker.Count(password)
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", name, err)
+			}
+			if count != expected {
+				t.Errorf("%s: expected %s to appear %d times, got %d", name, password, expected, count)
+			}
+		}
+		if count, err := checker.Count("Correct-Horse-42-Battery"); err != nil || count != 0 {
+			t.Errorf("%s: expected password not to be breached, got %d, %v", name, count, err)
+		}
+	}
+
+	policy := NewPolicy(&Policy{Breached: NewBreachedChecker(&FileRangeSource{Path: file})})
+	codes := _violationCodes(policy.Validate("password"))
+	if len(codes) != 1 || codes[0] != ViolationBreached {
+		t.Errorf("expected BREACHED violation, got %v", codes)
+	}
+}
@@ -0,0 +1,139 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+	"math/bits"
+	"strings"
+)
+
+// Maximum scrypt parameters accepted by Compare, so a crafted hash cannot make
+// the verification allocate or compute arbitrarily much. Scrypt uses 128*N*r bytes.
+const (
+	maxScryptMemory = 1 << 30 // 1 GiB
+	maxScryptR      = 32
+	maxScryptP      = 16
+)
+
+type ScryptConfig struct {
+	// LogN is the base 2 logarithm of the CPU/memory cost parameter N.
+	// 128*N*R bytes must not exceed 1 GiB.
+	// Default is 15 (N=32768).
+	LogN int
+
+	// R is the block size parameter, at most 32.
+	// Default is 8.
+	R int
+
+	// P is the parallelization parameter, at most 16.
+	// Default is 1.
+	P int
+
+	// SaltLength is the length of the random salt in bytes.
+	// Default is 16.
+	SaltLength int
+
+	// KeyLength is the length of the generated hash in bytes.
+	// Default is 32.
+	KeyLength int
+}
+
+// ScryptHasher hashes passwords with scrypt into PHC strings:
+// $scrypt$ln=15,r=8,p=1$<salt>$<hash>
+type ScryptHasher struct {
+	config *ScryptConfig
+}
+
+// NewScryptHasher creates a ScryptHasher, rejecting parameters above the maxima
+// accepted by Compare, as their hashes could never be verified.
+func NewScryptHasher(config *ScryptConfig) (*ScryptHasher, error) {
+	if config == nil {
+		config = &ScryptConfig{}
+	}
+	if config.LogN == 0 {
+		config.LogN = 15
+	}
+	if config.R == 0 {
+		config.R = 8
+	}
+	if config.P == 0 {
+		config.P = 1
+	}
+	if config.SaltLength == 0 {
+		config.SaltLength = 16
+	}
+	if config.KeyLength == 0 {
+		config.KeyLength = 32
+	}
+	if config.LogN < 0 || config.R < 0 || config.P < 0 {
+		return nil, errors.New("password: scrypt parameters must be positive")
+	}
+	if _scryptParamsExceed(config.LogN, config.R, config.P) {
+		return nil, fmt.Errorf("password: scrypt parameters exceed the maximum of %d bytes, r=%d and p=%d",
+			maxScryptMemory, maxScryptR, maxScryptP)
+	}
+	return &ScryptHasher{config: config}, nil
+}
+
+func (h *ScryptHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.config.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	hash, err := scrypt.Key([]byte(password), salt, 1<<h.config.LogN, h.config.R, h.config.P, h.config.KeyLength)
+	if err != nil {
+		return "", err
+	}
+	params := fmt.Sprintf("ln=%d,r=%d,p=%d", h.config.LogN, h.config.R, h.config.P)
+	return _encodePHC("scrypt", 0, params, salt, hash), nil
+}
+
+func (h *ScryptHasher) Compare(encoded, password string) error {
+	p, err := _parsePHC(encoded)
+	if err != nil {
+		return err
+	}
+	if p.id != "scrypt" {
+		return ErrUnsupportedHash
+	}
+	ln, r, par := p.params["ln"], p.params["r"], p.params["p"]
+	if ln <= 0 || r <= 0 || par <= 0 || len(p.hash) == 0 {
+		return ErrInvalidHash
+	}
+	if _scryptParamsExceed(ln, r, par) {
+		return fmt.Errorf("%w: scrypt parameters exceed the maximum", ErrInvalidHash)
+	}
+
+	hash, err := scrypt.Key([]byte(password), p.salt, 1<<ln, r, par, len(p.hash))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+	if subtle.ConstantTimeCompare(hash, p.hash) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+	return nil
+}
+
+// _scryptParamsExceed reports whether the parameters exceed the maxima, scrypt using 128*N*r bytes.
+func _scryptParamsExceed(logN, r, p int) bool {
+	return logN >= bits.UintSize-1 || r > maxScryptR || p > maxScryptP || 128*r > maxScryptMemory>>logN
+}
+
+func (h *ScryptHasher) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, "$scrypt$")
+}
+
+func (h *ScryptHasher) NeedsRehash(encoded string) bool {
+	p, err := _parsePHC(encoded)
+	if err != nil || p.id != "scrypt" {
+		return true
+	}
+	return p.params["ln"] != h.config.LogN ||
+		p.params["r"] != h.config.R ||
+		p.params["p"] != h.config.P ||
+		len(p.salt) != h.config.SaltLength ||
+		len(p.hash) != h.config.KeyLength
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	// Token bucket
+	tokens float64
+	last   time.Time
+
+	// Sliding window
+	index      int64
+	curr, prev float64
+
+	expires time.Time
+}
+
+type memoryLimiter struct {
+	config *Config
+	now    func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryLimiter returns a Limiter storing the counters in memory, so each instance
+// of the service limits the requests it receives on its own.
+func NewMemoryLimiter(config *Config) Limiter {
+	if config == nil {
+		config = &Config{}
+	}
+	config._applyDefaults()
+	return &memoryLimiter{
+		config:  config,
+		now:     time.Now,
+		entries: map[string]*memoryEntry{},
+	}
+}
+
+func (l *memoryLimiter) Allow(_ context.Context, key string) (*Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l._sweep(now)
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &memoryEntry{tokens: float64(l.config.Burst), last: now}
+		l.entries[key] = entry
+	}
+	if l.config.Algorithm == SlidingWindow {
+		return l._slidingWindow(entry, now), nil
+	}
+	return l._tokenBucket(entry, now), nil
+}
+
+func (l *memoryLimiter) _tokenBucket(entry *memoryEntry, now time.Time) *Result {
+	capacity := float64(l.config.Burst)
+	// Tokens per nanosecond
+	rate := float64(l.config.Limit) / float64(l.config.Period)
+
+	entry.tokens = math.Min(capacity, entry.tokens+float64(max(now.Sub(entry.last), 0))*rate)
+	entry.last = now
+
+	result := &Result{Limit: l.config.Burst}
+	if entry.tokens >= 1 {
+		entry.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - entry.tokens) / rate))
+	}
+	result.Remaining = int(entry.tokens)
+	result.Reset = time.Duration(math.Ceil((capacity - entry.tokens) / rate))
+	entry.expires = now.Add(result.Reset)
+	return result
+}
+
+func (l *memoryLimiter) _slidingWindow(entry *memoryEntry, now time.Time) *Result {
+	limit := float64(l.config.Limit)
+	period := l.config.Period
+	index := now.UnixNano() / int64(period)
+	elapsed := time.Duration(now.UnixNano() - index*int64(period))
+
+	switch entry.index {
+	case index:
+	case index - 1:
+		entry.prev, entry.curr = entry.curr, 0
+	default:
+		entry.prev, entry.curr = 0, 0
+	}
+	entry.index = index
+
+	count := entry.prev*float64(period-elapsed)/float64(period) + entry.curr
+	result := &Result{Limit: l.config.Limit, Reset: period - elapsed}
+	if count+1 <= limit {
+		entry.curr++
+		count++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = _retryAfterWindow(limit, entry.prev, entry.curr, elapsed, period)
+	}
+	result.Remaining = max(int(limit-count), 0)
+	entry.expires = now.Add(2*period - elapsed)
+	return result
+}
+
+// _sweep removes the expired entries, at most once per period.
+func (l *memoryLimiter) _sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.config.Period {
+		return
+	}
+	l.lastSweep = now
+	for key, entry := range l.entries {
+		if now.After(entry.expires) {
+			delete(l.entries, key)
+		}
+	}
+}
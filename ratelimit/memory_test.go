@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func _memoryLimiter(config *Config, now *time.Time) *memoryLimiter {
+	l := NewMemoryLimiter(config).(*memoryLimiter)
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := _memoryLimiter(&Config{Limit: 2, Period: time.Second, Burst: 3}, &now)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if r, _ := l.Allow(ctx, "a"); !r.Allowed || r.Remaining != 2-i {
+			t.Fatalf("request %d: unexpected result %+v", i, r)
+		}
+	}
+	r, _ := l.Allow(ctx, "a")
+	if r.Allowed || r.Limit != 3 || r.RetryAfter != 500*time.Millisecond || r.Reset != 1500*time.Millisecond {
+		t.Fatalf("expected the burst to be exhausted, got %+v", r)
+	}
+	if r, _ := l.Allow(ctx, "b"); !r.Allowed {
+		t.Errorf("keys must not share their bucket")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if r, _ := l.Allow(ctx, "a"); !r.Allowed || r.Remaining != 0 {
+		t.Errorf("expected one token after the refill, got %+v", r)
+	}
+}
+
+func TestMemorySlidingWindow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := _memoryLimiter(&Config{Algorithm: SlidingWindow, Limit: 4, Period: time.Second}, &now)
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		if r, _ := l.Allow(ctx, "a"); !r.Allowed || r.Remaining != 3-i {
+			t.Fatalf("request %d: unexpected result %+v", i, r)
+		}
+	}
+	r, _ := l.Allow(ctx, "a")
+	if r.Allowed || r.Reset != time.Second || r.RetryAfter != 1250*time.Millisecond {
+		t.Fatalf("expected the window to be full, got %+v", r)
+	}
+
+	// Half of the previous window still counts: 4 * 0.5 = 2 requests
+	now = now.Add(1500 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if r, _ := l.Allow(ctx, "a"); !r.Allowed {
+			t.Fatalf("request %d: expected to be allowed in the next window, got %+v", i, r)
+		}
+	}
+	r, _ = l.Allow(ctx, "a")
+	if r.Allowed || r.RetryAfter != 250*time.Millisecond {
+		t.Errorf("expected the sliding window to be full, got %+v", r)
+	}
+}
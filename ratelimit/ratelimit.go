@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"log/slog"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Algorithm is the rate limiting algorithm of a Limiter.
+type Algorithm string
+
+const (
+	// TokenBucket refills Config.Limit tokens per Config.Period in a bucket of Config.Burst tokens,
+	// each request taking one token. It allows short bursts above the average rate.
+	TokenBucket Algorithm = "token_bucket"
+
+	// SlidingWindow allows Config.Limit requests in any window of Config.Period. The count of
+	// the previous fixed window is weighted by its overlap with the sliding window, which keeps
+	// the state to two counters per key.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+type Config struct {
+	// Algorithm is the rate limiting algorithm.
+	// Default is TokenBucket.
+	Algorithm Algorithm
+
+	// Limit is the number of requests allowed per Period.
+	// Default is 100.
+	Limit int
+
+	// Period is the duration of the limit.
+	// Default is 1 minute.
+	Period time.Duration
+
+	// Burst is the capacity of the token bucket. It is ignored by the sliding window.
+	// Default is Limit.
+	Burst int
+
+	// Prefix is the prefix of the Redis keys.
+	// Default is "ratelimit:".
+	Prefix string
+}
+
+func (c *Config) _applyDefaults() {
+	if c.Algorithm == "" {
+		c.Algorithm = TokenBucket
+	}
+	if c.Limit <= 0 {
+		c.Limit = 100
+	}
+	if c.Period <= 0 {
+		c.Period = time.Minute
+	}
+	if c.Burst <= 0 {
+		c.Burst = c.Limit
+	}
+	if c.Prefix == "" {
+		c.Prefix = "ratelimit:"
+	}
+}
+
+// quota is the number of requests a client can make at once, reported as the limit.
+func (c *Config) _quota() int {
+	if c.Algorithm == TokenBucket {
+		return c.Burst
+	}
+	return c.Limit
+}
+
+// Result is the outcome of a request against the limit of a key.
+type Result struct {
+	// Allowed reports whether the request is within the limit.
+	Allowed bool
+
+	// Limit is the number of requests a client can make at once: the bucket capacity
+	// of the token bucket, or the limit of the sliding window.
+	Limit int
+
+	// Remaining is the number of requests left.
+	Remaining int
+
+	// Reset is the time until the limit is fully restored for the token bucket,
+	// or until the end of the current window for the sliding window.
+	Reset time.Duration
+
+	// RetryAfter is the time until the next request is allowed, when the request is rejected.
+	RetryAfter time.Duration
+}
+
+// Limiter counts the requests of the clients, identified by a key, against a limit.
+type Limiter interface {
+	// Allow counts a request of the key and reports whether it is within the limit.
+	Allow(ctx context.Context, key string) (*Result, error)
+}
+
+/*
+NewLimiter returns a Limiter storing the counters in Redis, shared by all the instances
+of the service, e.g. with the client of redis.NewConnection. While Redis is unreachable,
+the requests are limited by an in-memory Limiter, per instance.
+Without a client, it returns the in-memory Limiter.
+
+Parameters:
+  - client: The Redis client, may be nil.
+  - config: The configuration of the limit, may be nil for the defaults.
+*/
+func NewLimiter(client *redis.Client, config *Config) Limiter {
+	if client == nil {
+		return NewMemoryLimiter(config)
+	}
+	return &fallbackLimiter{
+		primary:  NewRedisLimiter(client, config),
+		fallback: NewMemoryLimiter(config),
+	}
+}
+
+type fallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+
+	// degraded is true while the requests are limited by the fallback, so the switches
+	// are logged once instead of on every request
+	degraded atomic.Bool
+}
+
+func (l *fallbackLimiter) Allow(ctx context.Context, key string) (*Result, error) {
+	result, err := l.primary.Allow(ctx, key)
+	if err == nil {
+		if l.degraded.CompareAndSwap(true, false) {
+			slog.InfoContext(ctx, "RateLimit: Redis is reachable again, leaving the in-memory limiter")
+		}
+		return result, nil
+	}
+	if l.degraded.CompareAndSwap(false, true) {
+		slog.WarnContext(ctx, "RateLimit: Failed to reach Redis, falling back to the in-memory limiter", "error", err.Error())
+	}
+	return l.fallback.Allow(ctx, key)
+}
+
+// _retryAfterWindow returns the time until a sliding window allows one more request, given
+// the counts of the previous and current windows and the time elapsed in the current window.
+func _retryAfterWindow(limit, prev, curr float64, elapsed, period time.Duration) time.Duration {
+	p := float64(period)
+	if curr <= limit-1 {
+		// Wait for the previous window to slide out enough
+		needed := p * (1 - (limit-1-curr)/prev)
+		return time.Duration(math.Ceil(needed)) - elapsed
+	}
+	// The current window becomes the previous one
+	needed := p * (1 - (limit-1)/curr)
+	return period - elapsed + time.Duration(math.Ceil(math.Max(needed, 0)))
+}
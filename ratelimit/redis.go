@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// The scripts run atomically on the Redis clock, so the instances of the service
+// share the counters without locking. They return {allowed, remaining, reset_ms, retry_ms}.
+
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2]) / tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed, retry = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+local reset = math.ceil((capacity - tokens) / rate)
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], reset + 1000)
+return {allowed, math.floor(tokens), reset, retry}
+`)
+
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local index = math.floor(now / period)
+local elapsed = now - index * period
+
+local state = redis.call('HMGET', KEYS[1], 'index', 'curr', 'prev')
+local stored = tonumber(state[1]) or index
+local curr, prev = tonumber(state[2]) or 0, tonumber(state[3]) or 0
+if stored == index - 1 then
+	prev, curr = curr, 0
+elseif stored ~= index then
+	prev, curr = 0, 0
+end
+
+local count = prev * (period - elapsed) / period + curr
+local allowed, retry = 0, 0
+if count + 1 <= limit then
+	curr = curr + 1
+	count = count + 1
+	allowed = 1
+elseif curr <= limit - 1 then
+	retry = math.ceil(period * (1 - (limit - 1 - curr) / prev)) - elapsed
+else
+	retry = period - elapsed + math.ceil(math.max(0, period * (1 - (limit - 1) / curr)))
+end
+
+redis.call('HSET', KEYS[1], 'index', index, 'curr', curr, 'prev', prev)
+redis.call('PEXPIRE', KEYS[1], 2 * period - elapsed)
+return {allowed, math.max(0, math.floor(limit - count)), period - elapsed, retry}
+`)
+
+type redisLimiter struct {
+	client *redis.Client
+	config *Config
+}
+
+// NewRedisLimiter returns a Limiter storing the counters in Redis, without fallback:
+// the errors of Redis are returned. See NewLimiter.
+func NewRedisLimiter(client *redis.Client, config *Config) Limiter {
+	if config == nil {
+		config = &Config{}
+	}
+	config._applyDefaults()
+	return &redisLimiter{client: client, config: config}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string) (*Result, error) {
+	keys := []string{l.config.Prefix + key}
+	period := l.config.Period.Milliseconds()
+
+	var values []int64
+	var err error
+	switch l.config.Algorithm {
+	case SlidingWindow:
+		values, err = slidingWindowScript.Run(ctx, l.client, keys, l.config.Limit, period).Int64Slice()
+	default:
+		values, err = tokenBucketScript.Run(ctx, l.client, keys, l.config.Burst, l.config.Limit, period).Int64Slice()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("ratelimit: unexpected script result %v", values)
+	}
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      l.config._quota(),
+		Remaining:  int(values[1]),
+		Reset:      time.Duration(values[2]) * time.Millisecond,
+		RetryAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func _redisLimiter(t *testing.T, config *Config) (Limiter, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	server.SetTime(time.Unix(1_700_000_000, 0))
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewRedisLimiter(client, config), server
+}
+
+func TestRedisTokenBucket(t *testing.T) {
+	l, server := _redisLimiter(t, &Config{Limit: 2, Period: time.Second, Burst: 3})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if r, err := l.Allow(ctx, "a"); err != nil || !r.Allowed || r.Remaining != 2-i {
+			t.Fatalf("request %d: unexpected result %+v %v", i, r, err)
+		}
+	}
+	r, _ := l.Allow(ctx, "a")
+	if r.Allowed || r.Limit != 3 || r.RetryAfter != 500*time.Millisecond || r.Reset != 1500*time.Millisecond {
+		t.Fatalf("expected the burst to be exhausted, got %+v", r)
+	}
+	if r, _ := l.Allow(ctx, "b"); !r.Allowed {
+		t.Errorf("keys must not share their bucket")
+	}
+	if ttl := server.TTL("ratelimit:a"); ttl <= 0 {
+		t.Errorf("expected the bucket to expire, got ttl %s", ttl)
+	}
+
+	server.SetTime(time.Unix(1_700_000_000, 0).Add(500 * time.Millisecond))
+	if r, _ := l.Allow(ctx, "a"); !r.Allowed || r.Remaining != 0 {
+		t.Errorf("expected one token after the refill, got %+v", r)
+	}
+}
+
+func TestRedisSlidingWindow(t *testing.T) {
+	l, server := _redisLimiter(t, &Config{Algorithm: SlidingWindow, Limit: 4, Period: time.Second})
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		if r, err := l.Allow(ctx, "a"); err != nil || !r.Allowed || r.Remaining != 3-i {
+			t.Fatalf("request %d: unexpected result %+v %v", i, r, err)
+		}
+	}
+	r, _ := l.Allow(ctx, "a")
+	if r.Allowed || r.Reset != time.Second || r.RetryAfter != 1250*time.Millisecond {
+		t.Fatalf("expected the window to be full, got %+v", r)
+	}
+
+	// Half of the previous window still counts: 4 * 0.5 = 2 requests
+	server.SetTime(time.Unix(1_700_000_000, 0).Add(1500 * time.Millisecond))
+	for i := 0; i < 2; i++ {
+		if r, _ := l.Allow(ctx, "a"); !r.Allowed {
+			t.Fatalf("request %d: expected to be allowed in the next window, got %+v", i, r)
+		}
+	}
+	r, _ = l.Allow(ctx, "a")
+	if r.Allowed || r.RetryAfter != 250*time.Millisecond {
+		t.Errorf("expected the sliding window to be full, got %+v", r)
+	}
+}
+
+func TestLimiterFallback(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	defer client.Close()
+	l := NewLimiter(client, &Config{Limit: 1, Period: time.Minute})
+	server.Close()
+
+	ctx := context.Background()
+	if r, err := l.Allow(ctx, "a"); err != nil || !r.Allowed {
+		t.Fatalf("expected the in-memory limiter to allow the request, got %+v %v", r, err)
+	}
+	if r, err := l.Allow(ctx, "a"); err != nil || r.Allowed {
+		t.Errorf("expected the in-memory limiter to limit the requests, got %+v %v", r, err)
+	}
+	if n := strings.Count(logs.String(), "falling back to the in-memory limiter"); n != 1 {
+		t.Errorf("expected the fallback to be logged once, got %d times", n)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Package reqctx stores the request metadata (request ID, trace ID, tenant ID, ...) in a
+context.Context under typed keys, so it can not collide with the keys of other packages.
+
+The same getters read the metadata set by http.ContextMiddleware, from the Fiber
+c.Context() (backed by Locals) or c.UserContext(), and by the Kafka and RabbitMQ consumers:
+
+	func (s *UserService) Create(ctx context.Context, ...) error {
+		tenantID := reqctx.TenantIDFrom(ctx)
+		...
+	}
+*/
+package reqctx
+
+import (
+	"context"
+)
+
+type ctxKey string
+
+// String returns the name of the key, also used as the log attribute name.
+func (k ctxKey) String() string {
+	return string(k)
+}
+
+const (
+	RequestIDKey ctxKey = "request_id"
+	TraceIDKey   ctxKey = "trace_id"
+	TenantIDKey  ctxKey = "tenant_id"
+	HostKey      ctxKey = "host"
+	RequestIPKey ctxKey = "request_ip"
+	LangKey      ctxKey = "lang"
+)
+
+var keys = []ctxKey{RequestIDKey, TraceIDKey, TenantIDKey, HostKey, RequestIPKey, LangKey}
+
+func _value(ctx context.Context, key ctxKey) string {
+	if ctx == nil {
+		return ""
+	}
+	value, _ := ctx.Value(key).(string)
+	return value
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, requestID)
+}
+
+func RequestIDFrom(ctx context.Context) string {
+	return _value(ctx, RequestIDKey)
+}
+
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, TraceIDKey, traceID)
+}
+
+func TraceIDFrom(ctx context.Context) string {
+	return _value(ctx, TraceIDKey)
+}
+
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, TenantIDKey, tenantID)
+}
+
+func TenantIDFrom(ctx context.Context) string {
+	return _value(ctx, TenantIDKey)
+}
+
+func WithHost(ctx context.Context, host string) context.Context {
+	return context.WithValue(ctx, HostKey, host)
+}
+
+func HostFrom(ctx context.Context) string {
+	return _value(ctx, HostKey)
+}
+
+func WithRequestIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, RequestIPKey, ip)
+}
+
+func RequestIPFrom(ctx context.Context) string {
+	return _value(ctx, RequestIPKey)
+}
+
+func WithLang(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, LangKey, lang)
+}
+
+func LangFrom(ctx context.Context) string {
+	return _value(ctx, LangKey)
+}
+
+// Lookup returns the metadata stored under the key with the given name, e.g. "request_id".
+// It returns false for unknown names or when the value is not set.
+func Lookup(ctx context.Context, name string) (string, bool) {
+	for _, key := range keys {
+		if key.String() == name {
+			value := _value(ctx, key)
+			return value, value != ""
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,39 @@
+package reqctx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAccessors(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithTenantID(ctx, "tenant-1")
+
+	if got := RequestIDFrom(ctx); got != "req-1" {
+		t.Errorf("RequestIDFrom() = %q, want req-1", got)
+	}
+	if got := TenantIDFrom(ctx); got != "tenant-1" {
+		t.Errorf("TenantIDFrom() = %q, want tenant-1", got)
+	}
+	if got := TraceIDFrom(ctx); got != "" {
+		t.Errorf("TraceIDFrom() = %q, want empty", got)
+	}
+	// Plain string keys of other packages do not collide with the typed keys
+	ctx = context.WithValue(ctx, "request_id", "other") //nolint:staticcheck
+	if got := RequestIDFrom(ctx); got != "req-1" {
+		t.Errorf("RequestIDFrom() = %q after a string key was set, want req-1", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	ctx := WithTraceID(context.Background(), "trace-1")
+	if value, ok := Lookup(ctx, "trace_id"); !ok || value != "trace-1" {
+		t.Errorf("Lookup(trace_id) = %q, %v", value, ok)
+	}
+	if _, ok := Lookup(ctx, "request_id"); ok {
+		t.Errorf("Lookup(request_id) found a value that was not set")
+	}
+	if _, ok := Lookup(ctx, "unknown"); ok {
+		t.Errorf("Lookup(unknown) found a value")
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
 	"go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"io"
 )
 
 func NewGrpcLoggerExporter(ctx context.Context, host string, port int) (*otlploggrpc.Exporter, error) {
@@ -23,8 +27,59 @@ func NewGrpcLoggerExporter(ctx context.Context, host string, port int) (*otlplog
 }
 
 func NewLoggerProvider(exporter *otlploggrpc.Exporter, r *resource.Resource) (*log.LoggerProvider, error) {
-	return log.NewLoggerProvider(
-		log.WithResource(r),
-		log.WithProcessor(log.NewBatchProcessor(exporter)),
-	), nil
+	return _newLoggerProvider(exporter, r), nil
+}
+
+// _newLogExporter returns the log exporter selected by the configuration,
+// or nil for the NoneExporter.
+func _newLogExporter(ctx context.Context, cfg *Config, stdout io.Writer) (log.Exporter, error) {
+	switch cfg.LogsExporter {
+	case NoneExporter:
+		return nil, nil
+	case StdoutExporter:
+		return stdoutlog.New(stdoutlog.WithWriter(stdout))
+	}
+
+	endpoint, isURL, hasEndpoint := cfg._endpoint("logs")
+	if cfg.Protocol == HTTPProtocol {
+		var opts []otlploghttp.Option
+		if hasEndpoint && isURL {
+			opts = append(opts, otlploghttp.WithEndpointURL(endpoint))
+		} else if hasEndpoint {
+			opts = append(opts, otlploghttp.WithEndpoint(endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlploghttp.WithInsecure())
+		} else if cfg.TLSConfig != nil {
+			opts = append(opts, otlploghttp.WithTLSClientConfig(cfg.TLSConfig))
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlploghttp.WithHeaders(cfg.Headers))
+		}
+		return otlploghttp.New(ctx, opts...)
+	}
+
+	var opts []otlploggrpc.Option
+	if hasEndpoint && isURL {
+		opts = append(opts, otlploggrpc.WithEndpointURL(endpoint))
+	} else if hasEndpoint {
+		opts = append(opts, otlploggrpc.WithEndpoint(endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlploggrpc.WithInsecure())
+	} else if cfg.TLSConfig != nil {
+		opts = append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(cfg.TLSConfig)))
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlploggrpc.WithHeaders(cfg.Headers))
+	}
+	return otlploggrpc.New(ctx, opts...)
+}
+
+func _newLoggerProvider(exporter log.Exporter, r *resource.Resource) *log.LoggerProvider {
+	opts := []log.LoggerProviderOption{log.WithResource(r)}
+	if exporter != nil {
+		opts = append(opts, log.WithProcessor(log.NewBatchProcessor(exporter)))
+	}
+	return log.NewLoggerProvider(opts...)
 }
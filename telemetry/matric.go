@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
-	"time"
+	"io"
 )
 
 func NewGrpcMetricExporter(ctx context.Context, host string, port int) (*otlpmetricgrpc.Exporter, error) {
@@ -23,11 +27,85 @@ func NewGrpcMetricExporter(ctx context.Context, host string, port int) (*otlpmet
 	return exp, nil
 }
 
+// NewPrometheusReader returns a reader registering the metrics with prometheus.DefaultRegisterer,
+// so they are served by promhttp.Handler along with the Go runtime metrics.
+func NewPrometheusReader() (*prometheus.Exporter, error) {
+	return prometheus.New()
+}
+
 func NewMeterProvider(exporter *otlpmetricgrpc.Exporter, r *resource.Resource) (*metric.MeterProvider, error) {
-	return metric.NewMeterProvider(
-		metric.WithResource(r),
-		metric.WithReader(metric.NewPeriodicReader(exporter,
-			// Default is 1m. Set to 3s for demonstrative purposes.
-			metric.WithInterval(3*time.Second))),
-	), nil
+	return NewMeterProviderWithReaders(r, metric.NewPeriodicReader(exporter))
+}
+
+// NewMeterProviderWithReaders returns a meter provider collecting the metrics with every reader,
+// e.g. a periodic OTLP reader alongside a Prometheus reader.
+func NewMeterProviderWithReaders(r *resource.Resource, readers ...metric.Reader) (*metric.MeterProvider, error) {
+	opts := []metric.Option{metric.WithResource(r)}
+	for _, reader := range readers {
+		opts = append(opts, metric.WithReader(reader))
+	}
+	return metric.NewMeterProvider(opts...), nil
+}
+
+// _newMetricReader returns the reader of the metrics exporter, or nil for the NoneExporter.
+// The OTLP and stdout exporters are read periodically, every Config.MetricInterval.
+func _newMetricReader(ctx context.Context, cfg *Config, exporter Exporter, stdout io.Writer) (metric.Reader, error) {
+	var metricExporter metric.Exporter
+	var err error
+	switch exporter {
+	case NoneExporter:
+		return nil, nil
+	case PrometheusExporter:
+		return NewPrometheusReader()
+	case StdoutExporter:
+		metricExporter, err = stdoutmetric.New(stdoutmetric.WithWriter(stdout))
+	default:
+		metricExporter, err = _newOTLPMetricExporter(ctx, cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []metric.PeriodicReaderOption
+	if cfg.MetricInterval > 0 {
+		opts = append(opts, metric.WithInterval(cfg.MetricInterval))
+	}
+	return metric.NewPeriodicReader(metricExporter, opts...), nil
+}
+
+func _newOTLPMetricExporter(ctx context.Context, cfg *Config) (metric.Exporter, error) {
+	endpoint, isURL, hasEndpoint := cfg._endpoint("metrics")
+	if cfg.Protocol == HTTPProtocol {
+		var opts []otlpmetrichttp.Option
+		if hasEndpoint && isURL {
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(endpoint))
+		} else if hasEndpoint {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		} else if cfg.TLSConfig != nil {
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(cfg.TLSConfig))
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlpmetrichttp.WithHeaders(cfg.Headers))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	}
+
+	var opts []otlpmetricgrpc.Option
+	if hasEndpoint && isURL {
+		opts = append(opts, otlpmetricgrpc.WithEndpointURL(endpoint))
+	} else if hasEndpoint {
+		opts = append(opts, otlpmetricgrpc.WithEndpoint(endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	} else if cfg.TLSConfig != nil {
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(cfg.TLSConfig)))
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.Headers))
+	}
+	return otlpmetricgrpc.New(ctx, opts...)
 }
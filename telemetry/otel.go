@@ -2,41 +2,304 @@ package telemetry
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"github.com/ppabimanyu/compage/logger"
 	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"io"
 	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+type Protocol string
+
+const (
+	GRPCProtocol Protocol = "grpc"
+	HTTPProtocol Protocol = "http/protobuf"
+)
+
+type Exporter string
+
+const (
+	// OTLPExporter pushes the signal to the OTLP collector.
+	OTLPExporter Exporter = "otlp"
+
+	// StdoutExporter writes the signal to os.Stdout, or to Config.StdoutFile, for local development.
+	// The OTEL_*_EXPORTER value "console" is an alias.
+	StdoutExporter Exporter = "stdout"
+
+	// PrometheusExporter exposes the metrics to Prometheus scrapes, through
+	// prometheus.DefaultRegisterer. Enable http.Config.Prometheus to serve them on /metrics.
+	// It is only supported for metrics.
+	PrometheusExporter Exporter = "prometheus"
+
+	// NoneExporter disables the export of the signal.
+	NoneExporter Exporter = "none"
 )
 
 type Config struct {
 	ServiceName string
 	Version     string
-	GrpcHost    string
-	GrpcPort    int
+
+	// Deprecated: use Endpoint. When GrpcHost or GrpcPort is set and Endpoint is not,
+	// the collector is reached in plaintext on GrpcHost:GrpcPort as before.
+	GrpcHost string
+	// Deprecated: use Endpoint.
+	GrpcPort int
+
+	// Protocol is the OTLP protocol, GRPCProtocol or HTTPProtocol.
+	// Default is OTEL_EXPORTER_OTLP_PROTOCOL, or GRPCProtocol.
+	Protocol Protocol
+
+	// Endpoint is the OTLP collector of every signal, either "host:port" or a URL.
+	// With HTTPProtocol, the signal path (e.g. "/v1/traces") is appended to a URL.
+	// Default is OTEL_EXPORTER_OTLP_ENDPOINT, or localhost on the default port of the protocol.
+	Endpoint string
+
+	// TracesEndpoint, MetricsEndpoint and LogsEndpoint override Endpoint for one signal.
+	// A URL is used as is, including its path.
+	// Default is OTEL_EXPORTER_OTLP_<SIGNAL>_ENDPOINT, or Endpoint.
+	TracesEndpoint  string
+	MetricsEndpoint string
+	LogsEndpoint    string
+
+	// Insecure disables TLS towards the collector.
+	// Default is OTEL_EXPORTER_OTLP_INSECURE, or true when no endpoint is configured at all,
+	// so a local collector is reached in plaintext.
+	Insecure bool
+
+	// TLSConfig is the TLS configuration used towards the collector, e.g. with a private CA
+	// or client certificates. Default is the system configuration.
+	TLSConfig *tls.Config
+
+	// Headers are sent with every export, e.g. {"Authorization": "Bearer <token>"}.
+	// Default is OTEL_EXPORTER_OTLP_HEADERS.
+	Headers map[string]string
+
+	// TracesExporter selects where the traces are exported.
+	// Default is OTEL_TRACES_EXPORTER, or OTLPExporter.
+	TracesExporter Exporter
+
+	// MetricsExporters selects where the metrics are exported, several can be combined.
+	// Default is OTEL_METRICS_EXPORTER (comma separated), or OTLPExporter.
+	MetricsExporters []Exporter
+
+	// LogsExporter selects where the logs are exported.
+	// Default is OTEL_LOGS_EXPORTER, or OTLPExporter.
+	LogsExporter Exporter
+
+	// StdoutFile is the file the StdoutExporter appends to instead of os.Stdout.
+	StdoutFile string
+
+	// SampleRatio is the ratio of the root traces sampled, between 0 and 1. Child spans
+	// follow the decision of their parent. Set TracesExporter to NoneExporter to drop every trace.
+	// Default is OTEL_TRACES_SAMPLER, or every trace.
+	SampleRatio float64
+
+	// MetricInterval is the interval between two exports of the periodic metric readers.
+	// Default is OTEL_METRIC_EXPORT_INTERVAL, or 1 minute.
+	MetricInterval time.Duration
+
+	// RegisterGlobal registers the providers and the propagator as the OpenTelemetry globals,
+	// used by the http and msgbroker instrumentations.
+	// Default is false.
+	RegisterGlobal bool
+
+	// SlogBridge sends the slog records to the logger provider as well, see SlogBridge.
+	// Default is false.
+	SlogBridge bool
 }
 
-func SetupOtelSDK(ctx context.Context, cfg *Config) (func(context.Context) error, error) {
-	if cfg == nil {
-		err := errors.New("telemetry: Invalid configuration, must not be nil")
-		slog.ErrorContext(ctx, err.Error())
-		return nil, err
+// Providers holds the providers built by SetupOtelSDK.
+type Providers struct {
+	TracerProvider *sdktrace.TracerProvider
+	MeterProvider  *metric.MeterProvider
+	LoggerProvider *log.LoggerProvider
+
+	// Propagator propagates the W3C trace context and baggage.
+	Propagator propagation.TextMapPropagator
+
+	shutdown func(context.Context) error
+}
+
+// Shutdown flushes and stops the providers. It matches http.ShutdownHook:
+//
+//	server.OnShutdown("telemetry", providers.Shutdown)
+func (p *Providers) Shutdown(ctx context.Context) error {
+	return p.shutdown(ctx)
+}
+
+func _exporterFromEnv(value string) Exporter {
+	exporter := Exporter(strings.ToLower(strings.TrimSpace(value)))
+	if exporter == "console" {
+		return StdoutExporter
 	}
+	return exporter
+}
+
+func _hasEndpointEnv() bool {
+	for _, key := range []string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_LOGS_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_INSECURE",
+	} {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
 
+// _applyDefaults fills the configuration from the OTEL_* environment variables and the defaults.
+// The variables read natively by the exporters and the SDK (headers, sampler, metric interval, ...)
+// apply whenever the matching field is left empty.
+func (cfg *Config) _applyDefaults() {
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = os.Getenv("OTEL_SERVICE_NAME")
+	}
 	if cfg.ServiceName == "" {
 		cfg.ServiceName = "go-service"
 	}
 	if cfg.Version == "" {
 		cfg.Version = "1.0.0"
 	}
-	if cfg.GrpcHost == "" {
-		cfg.GrpcHost = "localhost"
+	if cfg.Protocol == "" {
+		cfg.Protocol = Protocol(os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"))
 	}
-	if cfg.GrpcPort == 0 {
-		cfg.GrpcPort = 4317 // Default OTLP gRPC port
+	if cfg.Protocol == "" {
+		cfg.Protocol = GRPCProtocol
 	}
 
+	if cfg.Endpoint == "" && (cfg.GrpcHost != "" || cfg.GrpcPort != 0) {
+		if cfg.GrpcHost == "" {
+			cfg.GrpcHost = "localhost"
+		}
+		if cfg.GrpcPort == 0 {
+			cfg.GrpcPort = 4317 // Default OTLP gRPC port
+		}
+		cfg.Endpoint = fmt.Sprintf("%s:%d", cfg.GrpcHost, cfg.GrpcPort)
+		cfg.Insecure = true
+	}
+	if cfg.Endpoint == "" && cfg.TracesEndpoint == "" && cfg.MetricsEndpoint == "" && cfg.LogsEndpoint == "" &&
+		cfg.TLSConfig == nil && !_hasEndpointEnv() {
+		cfg.Insecure = true
+	}
+
+	if cfg.TracesExporter == "" {
+		cfg.TracesExporter = _exporterFromEnv(os.Getenv("OTEL_TRACES_EXPORTER"))
+	}
+	if cfg.TracesExporter == "" {
+		cfg.TracesExporter = OTLPExporter
+	}
+	if len(cfg.MetricsExporters) == 0 {
+		for _, value := range strings.Split(os.Getenv("OTEL_METRICS_EXPORTER"), ",") {
+			if exporter := _exporterFromEnv(value); exporter != "" {
+				cfg.MetricsExporters = append(cfg.MetricsExporters, exporter)
+			}
+		}
+	}
+	if len(cfg.MetricsExporters) == 0 {
+		cfg.MetricsExporters = []Exporter{OTLPExporter}
+	}
+	if cfg.LogsExporter == "" {
+		cfg.LogsExporter = _exporterFromEnv(os.Getenv("OTEL_LOGS_EXPORTER"))
+	}
+	if cfg.LogsExporter == "" {
+		cfg.LogsExporter = OTLPExporter
+	}
+}
+
+// _endpoint returns the collector endpoint of the signal ("traces", "metrics" or "logs"),
+// whether it is a URL, and false when none is configured so the exporter default applies.
+func (cfg *Config) _endpoint(signal string) (endpoint string, isURL bool, ok bool) {
+	perSignal := map[string]string{
+		"traces":  cfg.TracesEndpoint,
+		"metrics": cfg.MetricsEndpoint,
+		"logs":    cfg.LogsEndpoint,
+	}[signal]
+	if perSignal != "" {
+		return perSignal, strings.Contains(perSignal, "://"), true
+	}
+	if cfg.Endpoint == "" {
+		return "", false, false
+	}
+	if !strings.Contains(cfg.Endpoint, "://") {
+		return cfg.Endpoint, false, true
+	}
+	if cfg.Protocol == HTTPProtocol {
+		return strings.TrimSuffix(cfg.Endpoint, "/") + "/v1/" + signal, true, true
+	}
+	return cfg.Endpoint, true, true
+}
+
+func (cfg *Config) _validate() error {
+	if cfg.Protocol != GRPCProtocol && cfg.Protocol != HTTPProtocol {
+		return fmt.Errorf("telemetry: Unsupported OTLP protocol %q", cfg.Protocol)
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return fmt.Errorf("telemetry: Invalid sample ratio %v, must be between 0 and 1", cfg.SampleRatio)
+	}
+	for _, exporter := range []Exporter{cfg.TracesExporter, cfg.LogsExporter} {
+		switch exporter {
+		case OTLPExporter, StdoutExporter, NoneExporter:
+		default:
+			return fmt.Errorf("telemetry: Unsupported traces or logs exporter %q", exporter)
+		}
+	}
+	for _, exporter := range cfg.MetricsExporters {
+		switch exporter {
+		case OTLPExporter, StdoutExporter, PrometheusExporter, NoneExporter:
+		default:
+			return fmt.Errorf("telemetry: Unsupported metrics exporter %q", exporter)
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) _usesStdout() bool {
+	if cfg.TracesExporter == StdoutExporter || cfg.LogsExporter == StdoutExporter {
+		return true
+	}
+	for _, exporter := range cfg.MetricsExporters {
+		if exporter == StdoutExporter {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+SetupOtelSDK builds the tracer, meter and logger providers described by the configuration.
+With Config.RegisterGlobal, they are registered as the OpenTelemetry globals along with a
+propagator of the W3C trace context and baggage; with Config.SlogBridge, the slog records
+are also sent to the logger provider.
+*/
+func SetupOtelSDK(ctx context.Context, cfg *Config) (*Providers, error) {
+	if cfg == nil {
+		err := errors.New("telemetry: Invalid configuration, must not be nil")
+		slog.ErrorContext(ctx, err.Error())
+		return nil, err
+	}
+	cfg._applyDefaults()
+	if err := cfg._validate(); err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		return nil, err
+	}
+
+	var stdoutFile *os.File
 	var shutdownFuncs []func(context.Context) error
 	shutdown := func(ctx context.Context) error {
 		var err error
@@ -44,6 +307,11 @@ func SetupOtelSDK(ctx context.Context, cfg *Config) (func(context.Context) error
 			err = errors.Join(err, fn(ctx))
 		}
 		shutdownFuncs = nil
+		// Closed once the providers have flushed
+		if stdoutFile != nil {
+			err = errors.Join(err, stdoutFile.Close())
+			stdoutFile = nil
+		}
 		return err
 	}
 
@@ -60,49 +328,75 @@ func SetupOtelSDK(ctx context.Context, cfg *Config) (func(context.Context) error
 		return nil, err
 	}
 
-	matrixGrpcExporter, err := NewGrpcMetricExporter(ctx, cfg.GrpcHost, cfg.GrpcPort)
-	if err != nil {
-		slog.ErrorContext(ctx, "telemetry: Failed to create metric exporter", "error", err.Error())
-		return nil, err
+	var stdout io.Writer = os.Stdout
+	if cfg.StdoutFile != "" && cfg._usesStdout() {
+		stdoutFile, err = os.OpenFile(cfg.StdoutFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			slog.ErrorContext(ctx, "telemetry: Failed to open stdout exporter file", "file", cfg.StdoutFile, "error", err.Error())
+			return nil, err
+		}
+		stdout = stdoutFile
+	}
+
+	var metricReaders []metric.Reader
+	for _, exporter := range cfg.MetricsExporters {
+		reader, err := _newMetricReader(ctx, cfg, exporter, stdout)
+		if err != nil {
+			slog.ErrorContext(ctx, "telemetry: Failed to create metric exporter", "exporter", exporter, "error", err.Error())
+			return nil, errors.Join(err, shutdown(ctx))
+		}
+		if reader != nil {
+			metricReaders = append(metricReaders, reader)
+		}
 	}
-	matrixProvider, err := NewMeterProvider(matrixGrpcExporter, r)
+	matrixProvider, err := NewMeterProviderWithReaders(r, metricReaders...)
 	if err != nil {
 		slog.ErrorContext(ctx, "telemetry: Failed to create meter provider", "error", err.Error())
-		return nil, err
+		return nil, errors.Join(err, shutdown(ctx))
 	}
 	shutdownFuncs = append(shutdownFuncs, matrixProvider.Shutdown)
 
-	traceGrpcExporter, err := NewGrpcTraceExporter(ctx, cfg.GrpcHost, cfg.GrpcPort)
+	traceExporter, err := _newTraceExporter(ctx, cfg, stdout)
 	if err != nil {
 		slog.ErrorContext(ctx, "telemetry: Failed to create trace exporter", "error", err.Error())
-		return nil, err
-	}
-	traceProvider, err := NewTracerProvider(traceGrpcExporter, r)
-	if err != nil {
-		slog.ErrorContext(ctx, "telemetry: Failed to create tracer provider", "error", err.Error())
-		return nil, err
+		return nil, errors.Join(err, shutdown(ctx))
 	}
+	traceProvider := _newTracerProvider(cfg, traceExporter, r)
 	shutdownFuncs = append(shutdownFuncs, traceProvider.Shutdown)
 
-	logGrpcExporter, err := NewGrpcLoggerExporter(ctx, cfg.GrpcHost, cfg.GrpcPort)
+	logExporter, err := _newLogExporter(ctx, cfg, stdout)
 	if err != nil {
 		slog.ErrorContext(ctx, "telemetry: Failed to create log exporter", "error", err.Error())
-		return nil, err
-	}
-	logProvider, err := NewLoggerProvider(logGrpcExporter, r)
-	if err != nil {
-		slog.ErrorContext(ctx, "telemetry: Failed to create logger provider", "error", err.Error())
-		return nil, err
+		return nil, errors.Join(err, shutdown(ctx))
 	}
+	logProvider := _newLoggerProvider(logExporter, r)
 	shutdownFuncs = append(shutdownFuncs, logProvider.Shutdown)
 
-	return shutdown, nil
+	providers := &Providers{
+		TracerProvider: traceProvider,
+		MeterProvider:  matrixProvider,
+		LoggerProvider: logProvider,
+		Propagator:     propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+		shutdown:       shutdown,
+	}
+	if cfg.RegisterGlobal {
+		otel.SetTracerProvider(traceProvider)
+		otel.SetMeterProvider(matrixProvider)
+		global.SetLoggerProvider(logProvider)
+		otel.SetTextMapPropagator(providers.Propagator)
+	}
+	if cfg.SlogBridge {
+		SlogBridge(logProvider)
+	}
+
+	return providers, nil
 }
 
+// SlogBridge sends the slog records to the logger provider, in addition to the handler
+// set up by logger.SetupLogger, see logger.Tee. Call it after logger.SetupLogger.
 func SlogBridge(provider *log.LoggerProvider) {
-	logger := otelslog.NewLogger(
+	logger.Tee(otelslog.NewHandler(
 		"otel-slog-bridge",
 		otelslog.WithLoggerProvider(provider),
-	)
-	slog.SetDefault(logger)
+	))
 }
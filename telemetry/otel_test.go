@@ -0,0 +1,103 @@
+package telemetry
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestApplyDefaultsFromEnv(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "billing")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector:4318")
+	t.Setenv("OTEL_TRACES_EXPORTER", "console")
+	t.Setenv("OTEL_METRICS_EXPORTER", "otlp,prometheus")
+
+	cfg := &Config{}
+	cfg._applyDefaults()
+	if cfg.ServiceName != "billing" || cfg.Protocol != HTTPProtocol {
+		t.Errorf("unexpected service %q or protocol %q", cfg.ServiceName, cfg.Protocol)
+	}
+	if cfg.TracesExporter != StdoutExporter || cfg.LogsExporter != OTLPExporter {
+		t.Errorf("unexpected exporters %q and %q", cfg.TracesExporter, cfg.LogsExporter)
+	}
+	if len(cfg.MetricsExporters) != 2 || cfg.MetricsExporters[1] != PrometheusExporter {
+		t.Errorf("unexpected metrics exporters %v", cfg.MetricsExporters)
+	}
+	// The endpoint is read by the exporters, TLS must stay enabled
+	if cfg.Insecure {
+		t.Errorf("expected TLS with an endpoint from the environment")
+	}
+}
+
+func TestApplyDefaultsLegacyGrpc(t *testing.T) {
+	cfg := &Config{GrpcHost: "collector"}
+	cfg._applyDefaults()
+	if cfg.Endpoint != "collector:4317" || !cfg.Insecure {
+		t.Errorf("unexpected endpoint %q (insecure %v)", cfg.Endpoint, cfg.Insecure)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	cfg := &Config{Protocol: HTTPProtocol, Endpoint: "https://collector:4318/", LogsEndpoint: "https://logs:4318/custom"}
+	tests := []struct {
+		signal   string
+		expected string
+	}{
+		{"traces", "https://collector:4318/v1/traces"},
+		{"metrics", "https://collector:4318/v1/metrics"},
+		{"logs", "https://logs:4318/custom"},
+	}
+	for _, test := range tests {
+		endpoint, isURL, ok := cfg._endpoint(test.signal)
+		if endpoint != test.expected || !isURL || !ok {
+			t.Errorf("%s: expected %q, got %q", test.signal, test.expected, endpoint)
+		}
+	}
+
+	cfg = &Config{Protocol: GRPCProtocol, Endpoint: "collector:4317"}
+	if endpoint, isURL, _ := cfg._endpoint("traces"); endpoint != "collector:4317" || isURL {
+		t.Errorf("unexpected gRPC endpoint %q", endpoint)
+	}
+	if _, _, ok := (&Config{})._endpoint("traces"); ok {
+		t.Errorf("expected no endpoint to let the exporter default apply")
+	}
+}
+
+func TestSetupOtelSDKStdoutFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "telemetry.log")
+	providers, err := SetupOtelSDK(context.Background(), &Config{
+		TracesExporter:   StdoutExporter,
+		MetricsExporters: []Exporter{NoneExporter},
+		LogsExporter:     NoneExporter,
+		StdoutFile:       file,
+		SampleRatio:      1,
+		RegisterGlobal:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() != providers.TracerProvider {
+		t.Errorf("the tracer provider was not registered globally")
+	}
+	if fields := otel.GetTextMapPropagator().Fields(); !slices.Contains(fields, "traceparent") || !slices.Contains(fields, "baggage") {
+		t.Errorf("unexpected propagator fields %v", fields)
+	}
+	_, span := otel.Tracer("test").Start(context.Background(), "stdout-span")
+	span.End()
+	if err := providers.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(file); err != nil || !strings.Contains(string(content), "stdout-span") {
+		t.Errorf("the span was not exported to the stdout file: %v", err)
+	}
+
+	_, err = SetupOtelSDK(context.Background(), &Config{TracesExporter: PrometheusExporter})
+	if err == nil || !strings.Contains(err.Error(), "prometheus") {
+		t.Errorf("expected the prometheus traces exporter to be rejected, got %v", err)
+	}
+}
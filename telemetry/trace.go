@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"io"
 )
 
 func NewGrpcTraceExporter(ctx context.Context, host string, port int) (*otlptrace.Exporter, error) {
@@ -29,3 +33,62 @@ func NewTracerProvider(exporter *otlptrace.Exporter, r *resource.Resource) (*sdk
 		sdktrace.WithResource(r),
 	), nil
 }
+
+// _newTraceExporter returns the span exporter selected by the configuration,
+// or nil for the NoneExporter.
+func _newTraceExporter(ctx context.Context, cfg *Config, stdout io.Writer) (sdktrace.SpanExporter, error) {
+	switch cfg.TracesExporter {
+	case NoneExporter:
+		return nil, nil
+	case StdoutExporter:
+		return stdouttrace.New(stdouttrace.WithWriter(stdout))
+	}
+
+	endpoint, isURL, hasEndpoint := cfg._endpoint("traces")
+	if cfg.Protocol == HTTPProtocol {
+		var opts []otlptracehttp.Option
+		if hasEndpoint && isURL {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		} else if hasEndpoint {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else if cfg.TLSConfig != nil {
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(cfg.TLSConfig))
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+
+	var opts []otlptracegrpc.Option
+	if hasEndpoint && isURL {
+		opts = append(opts, otlptracegrpc.WithEndpointURL(endpoint))
+	} else if hasEndpoint {
+		opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	} else if cfg.TLSConfig != nil {
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(cfg.TLSConfig)))
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// _newTracerProvider returns a tracer provider sampling the root traces with Config.SampleRatio.
+// Without a ratio, the SDK reads OTEL_TRACES_SAMPLER and defaults to sampling every trace.
+func _newTracerProvider(cfg *Config, exporter sdktrace.SpanExporter, r *resource.Resource) *sdktrace.TracerProvider {
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(r)}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	if cfg.SampleRatio > 0 {
+		opts = append(opts, sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))))
+	}
+	return sdktrace.NewTracerProvider(opts...)
+}
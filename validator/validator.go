@@ -36,7 +36,13 @@ func (v *Validator) Var(field interface{}, tag string) map[string]string {
 
 func (v *Validator) _formatValidationError(err error) map[string]string {
 	errors := make(map[string]string)
-	for _, err := range err.(validator.ValidationErrors) {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		// e.g. *validator.InvalidValidationError when the value is not a struct
+		errors["_"] = err.Error()
+		return errors
+	}
+	for _, err := range validationErrors {
 		switch err.Tag() {
 		case "required":
 			errors[err.Field()] = fmt.Sprintf("%s is required", err.Field())
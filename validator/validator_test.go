@@ -0,0 +1,12 @@
+package validator
+
+import (
+	"testing"
+)
+
+func TestValidatorInvalidValue(t *testing.T) {
+	errs := NewValidator().Struct("not a struct")
+	if len(errs) != 1 || errs["_"] == "" {
+		t.Errorf("expected the invalid value error, got %v", errs)
+	}
+}